@@ -3,41 +3,134 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
+	"sync"
 
 	"golang.org/x/net/html"
 )
 
 type Link struct {
-	Text string
-	Href string
+	Text string `json:"text"`
+	Href string `json:"href"`
+}
+
+// Result holds the links found in a single source (a file, a URL or stdin)
+type Result struct {
+	Source string
+	Links  []Link
+	Err    error
 }
 
 func main() {
-	flagHTMLFilename := flag.String("html", "ex.html", "The path to the HTML file to parse")
+	flagHTMLFilename := flag.String("html", "ex.html", "The path to the HTML file to parse (used when no arguments are given)")
+	flagFormat := flag.String("format", formatText, "The output format, one of: text, jsonl, csv")
+	flagConcurrency := flag.Int("concurrency", 4, "The number of sources to parse concurrently")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file|url|-]...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
-	f, err := os.Open(*flagHTMLFilename)
+	sources := flag.Args()
+	if len(sources) == 0 {
+		sources = []string{*flagHTMLFilename}
+	}
+
+	w, err := newWriter(*flagFormat, os.Stdout, len(sources))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var failed int
+	for res := range parseSources(sources, *flagConcurrency) {
+		if res.Err != nil {
+			failed++
+			log.Printf("Failed to parse %q: %v", res.Source, res.Err)
+		}
+		if err := w.Write(res); err != nil {
+			log.Fatalf("Failed to write result for %q: %v", res.Source, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatalf("Failed to flush output: %v", err)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// parseSources parses up to concurrency sources at the same time, but always
+// emits their results in the same order as sources
+func parseSources(sources []string, concurrency int) <-chan Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// every source gets its own buffered channel, so workers never block on
+	// a slow reader and the results can be drained in order
+	pending := make([]chan Result, len(sources))
+	for i := range pending {
+		pending[i] = make(chan Result, 1)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	go func() {
+		var wg sync.WaitGroup
+		for i, src := range sources {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(i int, src string) {
+				defer func() { <-sem; wg.Done() }()
+				pending[i] <- parseSource(src)
+			}(i, src)
+		}
+		wg.Wait()
+	}()
+
+	results := make(chan Result)
+	go func() {
+		defer close(results)
+		for _, p := range pending {
+			results <- <-p
+		}
+	}()
+	return results
+}
+
+func parseSource(src string) Result {
+	res := Result{Source: src}
+
+	r, err := openSource(src)
 	if err != nil {
-		log.Fatalf("Failed to open file %q: %v", *flagHTMLFilename, err)
+		res.Err = err
+		return res
 	}
-	defer f.Close()
+	defer r.Close()
+
+	res.Links, res.Err = parseLinks(r)
+	return res
+}
 
-	root, err := html.Parse(f)
+func parseLinks(r io.Reader) ([]Link, error) {
+	root, err := html.Parse(r)
 	if err != nil {
-		log.Fatalf("Failed to parse HTML: %v", err)
+		return nil, err
 	}
 
 	as := make(chan *html.Node)
 	go findAnchors(root, as)
+	var links []Link
 	for a := range as {
-		fmt.Println(Link{
+		links = append(links, Link{
 			Text: extractText(a),
 			Href: extractHref(a),
 		})
 	}
+	return links, nil
 }
 
 func findAnchors(n *html.Node, as chan *html.Node) {
@@ -1,6 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -75,6 +80,119 @@ func TestExtractHref(t *testing.T) {
 	}
 }
 
+func TestParseSources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "link")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var sources []string
+	for i, page := range []string{"/a", "/b", "/c", "/d", "/e"} {
+		src := filepath.Join(dir, page[1:]+".html")
+		doc := `<a href="` + page + `">Page</a>`
+		if err := ioutil.WriteFile(src, []byte(doc), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", src, err)
+		}
+		sources = append(sources, src)
+		if i == 2 {
+			sources = append(sources, filepath.Join(dir, "missing.html"))
+		}
+	}
+
+	var results []Result
+	for res := range parseSources(sources, 3) {
+		results = append(results, res)
+	}
+	if len(results) != len(sources) {
+		t.Fatalf("expected %d results, got %d", len(sources), len(results))
+	}
+	for i, res := range results {
+		if res.Source != sources[i] {
+			t.Fatalf("expected result %d to be for %q, got %q", i, sources[i], res.Source)
+		}
+		if strings.HasSuffix(res.Source, "missing.html") {
+			if res.Err == nil {
+				t.Fatalf("expected an error for %q", res.Source)
+			}
+			continue
+		}
+		if res.Err != nil {
+			t.Fatalf("unexpected error for %q: %v", res.Source, res.Err)
+		}
+		if len(res.Links) != 1 {
+			t.Fatalf("expected 1 link for %q, got %d", res.Source, len(res.Links))
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	results := []Result{
+		{Source: "ex.html", Links: []Link{{Text: "Login, now", Href: "/login"}}},
+		{Source: "missing.html", Err: errors.New("not found")},
+	}
+
+	cases := []struct {
+		name    string
+		format  string
+		sources int
+		output  string
+	}{
+		{
+			name:    "text",
+			format:  formatText,
+			sources: 1,
+			output:  "{Login, now /login}\n",
+		},
+		{
+			name:    "text with sources",
+			format:  formatText,
+			sources: 2,
+			output:  "ex.html: {Login, now /login}\n",
+		},
+		{
+			name:    "jsonl",
+			format:  formatJSONL,
+			sources: 2,
+			output: `{"source":"ex.html","text":"Login, now","href":"/login"}` + "\n" +
+				`{"source":"missing.html","error":"not found"}` + "\n",
+		},
+		{
+			name:    "csv",
+			format:  formatCSV,
+			sources: 2,
+			output: "source,text,href,error\n" +
+				"ex.html,\"Login, now\",/login,\n" +
+				"missing.html,,,not found\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := newWriter(c.format, &buf, c.sources)
+			if err != nil {
+				t.Fatalf("failed to create writer: %v", err)
+			}
+			for _, res := range results {
+				if err := w.Write(res); err != nil {
+					t.Fatalf("failed to write: %v", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("failed to flush: %v", err)
+			}
+			if buf.String() != c.output {
+				t.Fatalf("expected %q, got %q", c.output, buf.String())
+			}
+		})
+	}
+
+	if _, err := newWriter("xml", ioutil.Discard, 1); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
+
 func parse(t *testing.T, a string) *html.Node {
 	n, err := html.Parse(strings.NewReader(a))
 	if err != nil {
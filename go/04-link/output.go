@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// The list of supported output formats
+const (
+	formatText  = "text"
+	formatJSONL = "jsonl"
+	formatCSV   = "csv"
+)
+
+// writer writes the links of every parsed source in a specific format
+type writer interface {
+	Write(Result) error
+	Flush() error
+}
+
+// newWriter creates a writer of the format for the results of n sources
+func newWriter(format string, w io.Writer, n int) (writer, error) {
+	switch format {
+	case formatText:
+		return &textWriter{w: bufio.NewWriter(w), prefix: n > 1}, nil
+	case formatJSONL:
+		bw := bufio.NewWriter(w)
+		return &jsonlWriter{w: bw, enc: json.NewEncoder(bw)}, nil
+	case formatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// textWriter prints links in the same form the command always did, prefixed
+// by their source when there's more than one
+type textWriter struct {
+	w      *bufio.Writer
+	prefix bool
+}
+
+func (tw *textWriter) Write(res Result) error {
+	for _, l := range res.Links {
+		if tw.prefix {
+			if _, err := fmt.Fprintf(tw.w, "%s: ", res.Source); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(tw.w, "%v\n", l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tw *textWriter) Flush() error {
+	return tw.w.Flush()
+}
+
+// jsonlWriter writes a JSON object per line for every link, or a single line
+// holding the error if the source couldn't be parsed
+type jsonlWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+type jsonlRecord struct {
+	Source string `json:"source"`
+	Text   string `json:"text,omitempty"`
+	Href   string `json:"href,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (jw *jsonlWriter) Write(res Result) error {
+	if res.Err != nil {
+		return jw.enc.Encode(jsonlRecord{Source: res.Source, Error: res.Err.Error()})
+	}
+	for _, l := range res.Links {
+		if err := jw.enc.Encode(jsonlRecord{Source: res.Source, Text: l.Text, Href: l.Href}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (jw *jsonlWriter) Flush() error {
+	return jw.w.Flush()
+}
+
+// csvWriter writes a source,text,href,error row for every link, preceded by
+// a header row
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (cw *csvWriter) Write(res Result) error {
+	if !cw.wroteHeader {
+		if err := cw.w.Write([]string{"source", "text", "href", "error"}); err != nil {
+			return err
+		}
+		cw.wroteHeader = true
+	}
+	if res.Err != nil {
+		return cw.w.Write([]string{res.Source, "", "", res.Err.Error()})
+	}
+	for _, l := range res.Links {
+		if err := cw.w.Write([]string{res.Source, l.Text, l.Href, ""}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
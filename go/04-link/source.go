@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const stdinSource = "-"
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// openSource opens src for reading, where src is either "-" for stdin, an
+// http(s) URL or a path to a local file
+func openSource(src string) (io.ReadCloser, error) {
+	switch {
+	case src == stdinSource:
+		return ioutil.NopCloser(os.Stdin), nil
+	case strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://"):
+		res, err := httpClient.Get(src)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			res.Body.Close()
+			return nil, fmt.Errorf("unexpected status: %s", res.Status)
+		}
+		return res.Body, nil
+	default:
+		return os.Open(src)
+	}
+}
@@ -0,0 +1,460 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ramin0/live/go/sitemap/link"
+)
+
+// crawler fetches pages using a pool of workers, while making sure no host is
+// hit more often than its rate limit allows
+type crawler struct {
+	client      *http.Client
+	userAgent   string
+	concurrency int
+	retries     int
+	backoff     time.Duration
+	limiter     *hostLimiter
+	scope       *scope
+
+	// cache holds the pages of a previous crawl, used to make conditional
+	// requests and reuse the links of pages that didn't change
+	cache map[string]*pageInfo
+
+	robotsMu sync.Mutex
+	robots   map[string]*robotsEntry
+}
+
+// robotsEntry makes sure the robots.txt of a host is only fetched once, even
+// when several workers need it at the same time. A fetch given up because
+// its context is done isn't kept, so the next caller tries again.
+type robotsEntry struct {
+	mu      sync.Mutex
+	fetched bool
+	robots  *robots
+	err     error
+}
+
+type crawlerOptions struct {
+	Scope       *scope
+	Concurrency int
+	Delay       time.Duration
+	Timeout     time.Duration
+	Retries     int
+	Backoff     time.Duration
+	UserAgent   string
+}
+
+func newCrawler(opts crawlerOptions) *crawler {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	return &crawler{
+		client: &http.Client{
+			Timeout: opts.Timeout,
+			// redirects are followed by hand, see get
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		userAgent:   opts.UserAgent,
+		concurrency: opts.Concurrency,
+		retries:     opts.Retries,
+		backoff:     opts.Backoff,
+		limiter:     newHostLimiter(opts.Delay),
+		scope:       opts.Scope,
+		robots:      map[string]*robotsEntry{},
+	}
+}
+
+// fetchResult holds the outcome of fetching a single page
+type fetchResult struct {
+	URL  string
+	URLs []string
+	Err  error
+
+	// Disallowed is set when robots.txt doesn't allow crawling the page, in
+	// which case it's never fetched
+	Disallowed bool
+	// NoIndex is set when the page asks not to be indexed
+	NoIndex bool
+	// LastModified comes from the Last-Modified header, if any
+	LastModified time.Time
+
+	// StatusCode is the status of the final response, after following all of
+	// Redirects
+	StatusCode int
+	Redirects  []redirect
+
+	// FinalURL is the canonical url of the page Redirects ended on, if it's
+	// in scope
+	FinalURL string
+	// Canonical is the url of the page's <link rel="canonical">, if it's in
+	// scope
+	Canonical string
+
+	// ETag comes from the ETag header, if any
+	ETag string
+	// NotModified is set when the page didn't change since the previous
+	// crawl, in which case its links come from the cache
+	NotModified bool
+}
+
+// fetchAll fetches all of urls concurrently and returns their results in the
+// same order. Once ctx is done, the remaining urls aren't fetched and their
+// results hold ctx's error.
+func (c *crawler) fetchAll(ctx context.Context, urls []string) []fetchResult {
+	results := make([]fetchResult, len(urls))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < c.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i] = fetchResult{URL: urls[i], Err: err}
+					continue
+				}
+				results[i] = c.fetch(ctx, urls[i])
+			}
+		}()
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func (c *crawler) fetch(ctx context.Context, pageURL string) fetchResult {
+	result := fetchResult{URL: pageURL}
+
+	allowed, err := c.allowed(ctx, pageURL)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if !allowed {
+		result.Disallowed = true
+		return result
+	}
+
+	// only ask for the page if it changed since the previous crawl
+	header := http.Header{}
+	cached := c.cache[result.URL]
+	if cached != nil && len(cached.Redirects) == 0 {
+		if cached.ETag != "" {
+			header.Set("If-None-Match", cached.ETag)
+		}
+		if !cached.LastModified.IsZero() {
+			header.Set("If-Modified-Since", cached.LastModified.UTC().Format(http.TimeFormat))
+		}
+	}
+
+	// fetch the html page for this url
+	res, redirects, err := c.get(ctx, pageURL, header, maxRedirects, c.allowed)
+	result.Redirects = redirects
+	if errors.Is(err, errDisallowed) {
+		result.Disallowed = true
+		return result
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified && len(redirects) == 0 && cached != nil {
+		result.NotModified = true
+		result.StatusCode = cached.StatusCode
+		result.ETag = cached.ETag
+		result.LastModified = cached.LastModified
+		result.NoIndex = cached.NoIndex
+		result.Canonical = cached.Canonical
+		result.URLs = cached.Links
+		return result
+	}
+
+	result.StatusCode = res.StatusCode
+	result.ETag = res.Header.Get("ETag")
+	if res.StatusCode >= 400 {
+		result.Err = fmt.Errorf("unexpected status: %s", res.Status)
+		return result
+	}
+	if len(redirects) > 0 {
+		// links are relative to the page we ended up on
+		pageURL = res.Request.URL.String()
+		if finalURL, ok := c.scope.Resolve(pageURL, ""); ok {
+			result.FinalURL = finalURL
+		}
+	}
+
+	if lastModified, err := http.ParseTime(res.Header.Get("Last-Modified")); err == nil {
+		result.LastModified = lastModified
+	}
+
+	// parse the page and get all links
+	page, err := link.ParsePage(res.Body)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	// X-Robots-Tag works just like <meta name="robots">
+	for _, tag := range res.Header.Values("X-Robots-Tag") {
+		noIndex, noFollow := c.robotsTag(tag)
+		page.NoIndex = page.NoIndex || noIndex
+		page.NoFollow = page.NoFollow || noFollow
+	}
+	result.NoIndex = page.NoIndex
+	if page.Canonical != "" {
+		if canonical, ok := c.scope.Resolve(pageURL, page.Canonical); ok {
+			result.Canonical = canonical
+		}
+	}
+	if page.NoFollow {
+		return result
+	}
+
+	// we only care about the in-scope links we're allowed to follow
+	for _, l := range page.Links {
+		if l.NoFollow() {
+			continue
+		}
+		if url, ok := c.scope.Resolve(pageURL, l.Href); ok {
+			result.URLs = append(result.URLs, url)
+		}
+	}
+	return result
+}
+
+// robotsTag returns the directives of an X-Robots-Tag header, ignoring the
+// ones aimed at other bots, as in "otherbot: noindex"
+func (c *crawler) robotsTag(tag string) (noIndex, noFollow bool) {
+	if i := strings.Index(tag, ":"); i != -1 {
+		if bot := strings.TrimSpace(tag[:i]); !strings.ContainsAny(bot, ", ") {
+			if !strings.EqualFold(bot, userAgentToken(c.userAgent)) {
+				return false, false
+			}
+			tag = tag[i+1:]
+		}
+	}
+	return link.RobotsDirectives(tag)
+}
+
+// allowed reports whether the robots.txt of pageURL's host allows crawling it
+func (c *crawler) allowed(ctx context.Context, pageURL string) (bool, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return false, err
+	}
+	rbts, err := c.robotsFor(ctx, u)
+	if err != nil {
+		return false, err
+	}
+	return rbts.Allowed(u), nil
+}
+
+func (c *crawler) robotsFor(ctx context.Context, u *url.URL) (*robots, error) {
+	key := u.Scheme + "://" + u.Host
+
+	c.robotsMu.Lock()
+	entry, ok := c.robots[key]
+	if !ok {
+		entry = &robotsEntry{}
+		c.robots[key] = entry
+	}
+	c.robotsMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.fetched {
+		return entry.robots, entry.err
+	}
+
+	rbts, err := c.fetchRobots(ctx, key+"/robots.txt")
+	if err != nil {
+		err = fmt.Errorf("failed to fetch robots.txt: %w", err)
+		if ctx.Err() != nil {
+			return nil, err
+		}
+	} else if rbts.crawlDelay > 0 {
+		c.limiter.SetDelay(u.Host, rbts.crawlDelay)
+	}
+	entry.fetched, entry.robots, entry.err = true, rbts, err
+	return rbts, err
+}
+
+// fetchRobots fetches and parses the robots.txt of a host, following up to
+// maxRobotsRedirects redirects. A missing robots.txt, or one behind a
+// redirect that can't be followed, allows everything, while a failing one
+// (5xx) disallows everything, since we can't know what the site wants.
+func (c *crawler) fetchRobots(ctx context.Context, robotsURL string) (*robots, error) {
+	res, _, err := c.get(ctx, robotsURL, nil, maxRobotsRedirects, nil)
+	if errors.Is(err, errRedirect) {
+		return robotsAllowAll, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode >= 500:
+		return robotsDisallowAll, nil
+	case res.StatusCode >= 400:
+		return robotsAllowAll, nil
+	case isRedirect(res.StatusCode):
+		// a redirect without a Location
+		return robotsAllowAll, nil
+	}
+	return parseRobots(res.Body, c.userAgent)
+}
+
+// The number of redirects followed before giving up on a page, or on a
+// robots.txt
+const (
+	maxRedirects       = 10
+	maxRobotsRedirects = 5
+)
+
+// errRedirect is returned when a redirect chain is too long, or leads to a
+// Location that isn't an http or https url
+var errRedirect = errors.New("bad redirect")
+
+// errDisallowed is returned when a redirect leads to a page robots.txt
+// doesn't allow crawling
+var errDisallowed = errors.New("disallowed by robots.txt")
+
+// redirect is a single hop of a redirect chain
+type redirect struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location"`
+}
+
+// get fetches pageURL, following up to max redirects by hand so every hop is
+// recorded. The response is returned whatever its status code, so it's up to
+// the caller to close its body and decide what to do with it. header is only
+// sent with the first request, and redirects are only followed to the pages
+// allow allows, if it's not nil.
+func (c *crawler) get(ctx context.Context, pageURL string, header http.Header, max int, allow func(context.Context, string) (bool, error)) (*http.Response, []redirect, error) {
+	var redirects []redirect
+	for {
+		res, err := c.getOnce(ctx, pageURL, header)
+		if err != nil {
+			return nil, redirects, err
+		}
+
+		location := res.Header.Get("Location")
+		if !isRedirect(res.StatusCode) || location == "" {
+			return res, redirects, nil
+		}
+		res.Body.Close()
+
+		if len(redirects) == max {
+			return nil, redirects, fmt.Errorf("%w: stopped after %d redirects", errRedirect, max)
+		}
+
+		u, err := url.Parse(pageURL)
+		if err != nil {
+			return nil, redirects, err
+		}
+		next, err := u.Parse(location)
+		if err != nil {
+			return nil, redirects, fmt.Errorf("%w: %v", errRedirect, err)
+		}
+		if next.Scheme != "http" && next.Scheme != "https" {
+			return nil, redirects, fmt.Errorf("%w: can't follow %s", errRedirect, next)
+		}
+		redirects = append(redirects, redirect{
+			URL:        pageURL,
+			StatusCode: res.StatusCode,
+			Location:   next.String(),
+		})
+		pageURL = next.String()
+		if allow != nil {
+			allowed, err := allow(ctx, pageURL)
+			if err != nil {
+				return nil, redirects, err
+			}
+			if !allowed {
+				return nil, redirects, errDisallowed
+			}
+		}
+		header = nil
+	}
+}
+
+// getOnce fetches pageURL without following redirects, retrying with an
+// exponential backoff on network errors, 429s and 5xx responses
+func (c *crawler) getOnce(ctx context.Context, pageURL string, header http.Header) (*http.Response, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			backoff *= 2
+		}
+
+		if err := c.limiter.Wait(ctx, u.Host); err != nil {
+			return nil, err
+		}
+		res, err := c.do(ctx, pageURL, header)
+		if err == nil && (!retryable(res.StatusCode) || attempt >= c.retries) {
+			return res, nil
+		}
+		if err == nil {
+			res.Body.Close()
+		}
+		if attempt >= c.retries {
+			return nil, err
+		}
+	}
+}
+
+func (c *crawler) do(ctx context.Context, pageURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return c.client.Do(req)
+}
+
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCrawler_fetchAll(t *testing.T) {
+	var flakyHits int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "test-agent" {
+			t.Errorf("User-Agent: want %s, got %s", "test-agent", r.Header.Get("User-Agent"))
+		}
+		fmt.Fprint(w, `<a href="/a">A</a><a href="/b" rel="nofollow">B</a><a href="https://example.com">Out</a>`)
+	})
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: test-agent\nDisallow: /secret\n")
+	})
+	mux.HandleFunc("/noindex", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<meta name="robots" content="noindex, nofollow"><a href="/c">C</a>`)
+	})
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&flakyHits, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `<a href="/b">B</a>`)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := newCrawler(crawlerOptions{
+		Scope:       newTestScope(t, server.URL),
+		Concurrency: 2,
+		Timeout:     time.Second,
+		Retries:     2,
+		Backoff:     time.Millisecond,
+		UserAgent:   "test-agent",
+	})
+	results := c.fetchAll(context.Background(), []string{
+		server.URL,
+		server.URL + "/flaky",
+		server.URL + "/missing",
+		server.URL + "/secret",
+		server.URL + "/noindex",
+	})
+
+	if len(results) != 5 {
+		t.Fatalf("len(results): want %d, got %d", 5, len(results))
+	}
+	if results[0].Err != nil || len(results[0].URLs) != 1 || results[0].URLs[0] != server.URL+"/a" {
+		t.Errorf("results[0]: want [%s/a], got %v (err: %v)", server.URL, results[0].URLs, results[0].Err)
+	}
+	if results[1].Err != nil {
+		t.Errorf("results[1]: expected the retries to succeed, got %v", results[1].Err)
+	}
+	if flakyHits != 3 {
+		t.Errorf("flakyHits: want %d, got %d", 3, flakyHits)
+	}
+	if results[2].Err == nil {
+		t.Errorf("results[2]: expected an error for a 404")
+	}
+	if !results[3].Disallowed {
+		t.Errorf("results[3]: expected robots.txt to disallow it")
+	}
+	if !results[4].NoIndex || len(results[4].URLs) != 0 {
+		t.Errorf("results[4]: want noindex with no urls, got noindex=%v urls=%v", results[4].NoIndex, results[4].URLs)
+	}
+}
+
+func TestCrawler_fetchDisallowedRedirect(t *testing.T) {
+	var fetched int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /secret\n")
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/secret", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/secret", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetched, 1)
+		fmt.Fprint(w, `<a href="/hidden">Hidden</a>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := newCrawler(crawlerOptions{Scope: newTestScope(t, server.URL), UserAgent: "test-agent"})
+
+	res := c.fetch(context.Background(), server.URL+"/old")
+	if !res.Disallowed || res.Err != nil || len(res.URLs) != 0 {
+		t.Errorf("fetch(): want a disallowed page, got disallowed=%v err=%v urls=%v", res.Disallowed, res.Err, res.URLs)
+	}
+	if len(res.Redirects) != 1 || res.Redirects[0].Location != server.URL+"/secret" {
+		t.Errorf("fetch(): want the redirect to /secret, got %v", res.Redirects)
+	}
+	if n := atomic.LoadInt32(&fetched); n != 0 {
+		t.Errorf("fetch(): want /secret left alone, got %d requests", n)
+	}
+}
+
+func TestCrawler_robotsTag(t *testing.T) {
+	cases := []struct {
+		tag      string
+		noIndex  bool
+		noFollow bool
+	}{
+		{tag: "noindex", noIndex: true},
+		{tag: "NoIndex, NoFollow", noIndex: true, noFollow: true},
+		{tag: "none", noIndex: true, noFollow: true},
+		{tag: "noarchive, nosnippet"},
+		{tag: "test-agent: nofollow", noFollow: true},
+		{tag: "otherbot: noindex, nofollow"},
+		{tag: "unavailable_after: 25 Jun 2030 15:00:00 PST"},
+		{tag: "noindex, max-snippet: 20", noIndex: true},
+	}
+	c := newCrawler(crawlerOptions{UserAgent: "test-agent/1.0"})
+	for _, tc := range cases {
+		noIndex, noFollow := c.robotsTag(tc.tag)
+		if noIndex != tc.noIndex || noFollow != tc.noFollow {
+			t.Errorf("robotsTag(%q): want %v and %v, got %v and %v", tc.tag, tc.noIndex, tc.noFollow, noIndex, noFollow)
+		}
+	}
+}
+
+func TestCrawler_robotsFor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /secret\n")
+	}))
+	defer server.Close()
+	c := newCrawler(crawlerOptions{Scope: newTestScope(t, server.URL), UserAgent: "test-agent"})
+
+	// a fetch cut short by a canceled context isn't kept for later
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.allowed(ctx, server.URL+"/secret"); !errors.Is(err, context.Canceled) {
+		t.Errorf("allowed(): want %v, got %v", context.Canceled, err)
+	}
+	allowed, err := c.allowed(context.Background(), server.URL+"/secret")
+	if err != nil {
+		t.Fatalf("allowed() received an error: %v", err)
+	}
+	if allowed {
+		t.Errorf("allowed(): want false, got true")
+	}
+}
+
+func TestCrawler_fetchRobots(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rules.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /secret\n")
+	})
+	mux.HandleFunc("/moved/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/rules.txt", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/loop/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path, http.StatusFound)
+	})
+	mux.HandleFunc("/bad/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "mailto:webmaster@example.com", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := newCrawler(crawlerOptions{UserAgent: "test-agent"})
+
+	cases := []struct {
+		path    string
+		allowed bool
+	}{
+		// the rules a redirect leads to are the ones followed
+		{path: "/moved/robots.txt", allowed: false},
+		// too many redirects, or a broken one, are like a missing robots.txt
+		{path: "/loop/robots.txt", allowed: true},
+		{path: "/bad/robots.txt", allowed: true},
+	}
+	secret, _ := url.Parse(server.URL + "/secret")
+	for _, tc := range cases {
+		rbts, err := c.fetchRobots(context.Background(), server.URL+tc.path)
+		if err != nil {
+			t.Errorf("fetchRobots(%s) received an error: %v", tc.path, err)
+			continue
+		}
+		if got := rbts.Allowed(secret); got != tc.allowed {
+			t.Errorf("fetchRobots(%s).Allowed(/secret): want %v, got %v", tc.path, tc.allowed, got)
+		}
+	}
+}
+
+func TestHostLimiter_Wait(t *testing.T) {
+	l := newHostLimiter(20 * time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background(), "example.com"); err != nil {
+			t.Fatalf("Wait() received an error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("elapsed: want at least %v, got %v", 40*time.Millisecond, elapsed)
+	}
+
+	start = time.Now()
+	l.Wait(context.Background(), "other.com")
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("elapsed: want no delay for another host, got %v", elapsed)
+	}
+
+	// a long delay is cut short by canceling
+	l.SetDelay("slow.com", time.Hour)
+	l.Wait(context.Background(), "slow.com")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := l.Wait(ctx, "slow.com"); err != context.DeadlineExceeded {
+		t.Errorf("Wait(): want %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("elapsed: want Wait() to stop with its context, got %v", elapsed)
+	}
+}
+
+func newTestScope(t *testing.T, baseURL string) *scope {
+	s, err := newScope(baseURL, scopeOptions{StripParams: defaultStripParams})
+	if err != nil {
+		t.Fatalf("failed to create scope: %v", err)
+	}
+	return s
+}
@@ -2,4 +2,8 @@ module github.com/ramin0/live/go/sitemap
 
 go 1.14
 
-require golang.org/x/net v0.0.0-20200501053045-e0ff5e5a1de5
+require (
+	go.etcd.io/bbolt v1.3.6
+	golang.org/x/net v0.0.0-20200501053045-e0ff5e5a1de5
+	golang.org/x/sys v0.10.0 // indirect
+)
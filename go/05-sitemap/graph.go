@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// crawlGraph holds every page found while crawling a site, along with the
+// links between them
+type crawlGraph struct {
+	Pages map[string]*pageInfo
+	// Order holds the urls of Pages in the order they were discovered
+	Order []string
+
+	// dirty holds the urls of the pages that changed since the last
+	// checkpoint, and saved is the number of urls of Order that were saved
+	dirty map[string]bool
+	saved int
+}
+
+// pageInfo is what the crawl knows about a single page
+type pageInfo struct {
+	URL string `json:"url"`
+	// Depth is the number of links between the base url and this page
+	Depth int `json:"depth"`
+	// StatusCode is 0 for pages that were never fetched (e.g. disallowed by
+	// robots.txt) or whose fetch failed before getting a response
+	StatusCode int    `json:"status_code,omitempty"`
+	Err        string `json:"error,omitempty"`
+
+	Disallowed   bool      `json:"disallowed,omitempty"`
+	NoIndex      bool      `json:"noindex,omitempty"`
+	LastModified time.Time `json:"last_modified"`
+	// Canonical is the url of the page this one is a duplicate of, according
+	// to its <link rel="canonical">
+	Canonical string `json:"canonical,omitempty"`
+	ETag      string `json:"etag,omitempty"`
+	// NotModified is set when the page didn't change since the previous crawl
+	NotModified bool `json:"not_modified,omitempty"`
+
+	// Redirects is the chain of redirects followed from this page, ending at
+	// FinalURL
+	Redirects       []redirect `json:"redirects,omitempty"`
+	FinalURL        string     `json:"final_url,omitempty"`
+	FinalStatusCode int        `json:"final_status_code,omitempty"`
+
+	// Links are the in-scope urls this page links to, InboundLinks is the
+	// number of links pointing to this page and Referrers are the (unique)
+	// pages those links are on
+	Links        []string `json:"links,omitempty"`
+	InboundLinks int      `json:"inbound_links"`
+	Referrers    []string `json:"referrers,omitempty"`
+}
+
+func newCrawlGraph() *crawlGraph {
+	return &crawlGraph{
+		Pages: map[string]*pageInfo{},
+		dirty: map[string]bool{},
+	}
+}
+
+// add records a newly discovered page, and reports whether it's new
+func (g *crawlGraph) add(url string, depth int) (*pageInfo, bool) {
+	if p, ok := g.Pages[url]; ok {
+		return p, false
+	}
+	p := &pageInfo{URL: url, Depth: depth}
+	g.Pages[url] = p
+	g.Order = append(g.Order, url)
+	g.dirty[url] = true
+	return p, true
+}
+
+// link records a link from one page to another
+func (g *crawlGraph) link(from, to *pageInfo) {
+	g.dirty[from.URL] = true
+	g.dirty[to.URL] = true
+	from.Links = append(from.Links, to.URL)
+	to.InboundLinks++
+	for _, r := range to.Referrers {
+		if r == from.URL {
+			return
+		}
+	}
+	to.Referrers = append(to.Referrers, from.URL)
+}
+
+// crawl crawls baseURL and every in-scope page up to depth links away from
+// it. Pages at the last level are fetched, but their links aren't followed.
+//
+// If state isn't nil, progress is checkpointed to it after every batch of
+// pages, so an interrupted crawl can be resumed later. When ctx is done, the
+// crawl stops and returns the graph so far along with ctx's error.
+func crawl(ctx context.Context, c *crawler, baseURL string, depth int, state *crawlState, resume bool) (*crawlGraph, error) {
+	g := newCrawlGraph()
+	g.add(baseURL, 0)
+	queue := []string{baseURL}
+
+	if state != nil {
+		var err error
+		g, queue, c.cache, err = state.Start(baseURL, depth, resume)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for len(queue) > 0 {
+		results := c.fetchAll(ctx, queue)
+		queue = nil
+
+		for _, res := range results {
+			// pages that weren't fetched because we're stopping are kept for
+			// the next run
+			if ctx.Err() != nil && errors.Is(res.Err, ctx.Err()) {
+				queue = append(queue, res.URL)
+				continue
+			}
+			queue = append(queue, g.record(res, depth)...)
+		}
+
+		if state != nil {
+			if err := state.Checkpoint(g, queue); err != nil {
+				return g, err
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return g, err
+		}
+	}
+
+	if state != nil {
+		if err := state.Finish(); err != nil {
+			return g, err
+		}
+	}
+	return g, nil
+}
+
+// record records the result of fetching a page, and returns the urls of the
+// new pages it links to that should be fetched next
+func (g *crawlGraph) record(res fetchResult, depth int) []string {
+	p := g.Pages[res.URL]
+	g.dirty[p.URL] = true
+	if res.Err != nil {
+		p.Err = res.Err.Error()
+	}
+	p.Disallowed = res.Disallowed
+	p.StatusCode = res.StatusCode
+
+	// the page we ended up on after the redirects holds the actual
+	// content, so it's recorded as a page of its own
+	if len(res.Redirects) > 0 {
+		p.Redirects = res.Redirects
+		p.StatusCode = res.Redirects[0].StatusCode
+		p.FinalURL = res.Redirects[len(res.Redirects)-1].Location
+		p.FinalStatusCode = res.StatusCode
+
+		// without a final response, the chain itself is what failed
+		if res.StatusCode == 0 {
+			return nil
+		}
+		p.Err = ""
+		// redirects out of the crawl's scope are left alone
+		if res.FinalURL == "" {
+			return nil
+		}
+		final, isNew := g.add(res.FinalURL, p.Depth)
+		g.link(p, final)
+		if !isNew {
+			return nil
+		}
+		final.StatusCode = res.StatusCode
+		if res.Err != nil {
+			final.Err = res.Err.Error()
+		}
+		p = final
+	}
+	if res.Err != nil {
+		return nil
+	}
+	p.NoIndex = res.NoIndex
+	p.LastModified = res.LastModified
+	p.ETag = res.ETag
+	p.NotModified = res.NotModified
+
+	var queue []string
+	// the canonical page is crawled instead of this one, whatever the depth,
+	// but it isn't a link of this page
+	if res.Canonical != "" && res.Canonical != p.URL {
+		p.Canonical = res.Canonical
+		canonical, isNew := g.add(res.Canonical, p.Depth)
+		if isNew {
+			queue = append(queue, canonical.URL)
+		}
+	}
+	for _, url := range res.URLs {
+		// links to new pages are only followed until we're too deep
+		if _, ok := g.Pages[url]; !ok && p.Depth >= depth {
+			continue
+		}
+		sub, isNew := g.add(url, p.Depth+1)
+		g.link(p, sub)
+		if isNew {
+			queue = append(queue, url)
+		}
+	}
+	return queue
+}
+
+// SitemapPages returns the pages that belong in a sitemap: the ones that
+// were fetched successfully and are allowed to be indexed
+func (g *crawlGraph) SitemapPages() []*pageInfo {
+	var pages []*pageInfo
+	for _, url := range g.Order {
+		p := g.Pages[url]
+		if p.Disallowed || p.NoIndex || p.Canonical != "" || p.Err != "" ||
+			p.StatusCode < 200 || p.StatusCode > 299 {
+			continue
+		}
+		pages = append(pages, p)
+	}
+	return pages
+}
+
+// BrokenPages returns the pages that responded with a 4xx or 5xx status
+func (g *crawlGraph) BrokenPages() []*pageInfo {
+	var pages []*pageInfo
+	for _, url := range g.Order {
+		if p := g.Pages[url]; p.StatusCode >= 400 {
+			pages = append(pages, p)
+		}
+	}
+	return pages
+}
+
+// RedirectedPages returns the pages that redirected somewhere else
+func (g *crawlGraph) RedirectedPages() []*pageInfo {
+	var pages []*pageInfo
+	for _, url := range g.Order {
+		if p := g.Pages[url]; len(p.Redirects) > 0 {
+			pages = append(pages, p)
+		}
+	}
+	return pages
+}
+
+// FailedPages returns the pages that couldn't be fetched at all
+func (g *crawlGraph) FailedPages() []*pageInfo {
+	var pages []*pageInfo
+	for _, url := range g.Order {
+		if p := g.Pages[url]; p.Err != "" && p.StatusCode == 0 {
+			pages = append(pages, p)
+		}
+	}
+	return pages
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCrawl(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `<a href="/a">A</a><a href="/old">Old</a><a href="/gone">Gone</a><a href="/a#top">A again</a><a href="/dup?utm_source=x">Dup</a>`)
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<a href="deep">Deep</a><a href="/gone">Gone</a>`)
+	})
+	mux.HandleFunc("/dup", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<link rel="canonical" href="/a"><p>Dup</p>`)
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/older", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/older", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<p>New</p>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	scope := newTestScope(t, server.URL)
+	c := newCrawler(crawlerOptions{Scope: scope, Concurrency: 2, Timeout: time.Second})
+	g, err := crawl(context.Background(), c, scope.Base(), 1, nil, false)
+	if err != nil {
+		t.Fatalf("crawl() received an error: %v", err)
+	}
+
+	var sitemap []string
+	for _, p := range g.SitemapPages() {
+		sitemap = append(sitemap, p.URL)
+	}
+	want := []string{server.URL + "/", server.URL + "/a", server.URL + "/new"}
+	if fmt.Sprint(sitemap) != fmt.Sprint(want) {
+		t.Errorf("SitemapPages(): want %v, got %v", want, sitemap)
+	}
+
+	if _, ok := g.Pages[server.URL+"/deep"]; ok {
+		t.Errorf("Pages: expected %s/deep to be too deep", server.URL)
+	}
+	if p := g.Pages[server.URL+"/a"]; p.Depth != 1 || p.InboundLinks != 2 || len(p.Referrers) != 1 {
+		t.Errorf("Pages[/a]: want depth 1 and 2 inbound links from a single page, got %d, %d and %v", p.Depth, p.InboundLinks, p.Referrers)
+	}
+	// a canonical isn't a link
+	if p := g.Pages[server.URL+"/dup"]; p == nil || p.Canonical != server.URL+"/a" || len(p.Links) != 0 {
+		t.Errorf("Pages[/dup]: want a canonical of %s/a and no links, got %+v", server.URL, p)
+	}
+
+	broken := g.BrokenPages()
+	if len(broken) != 1 || broken[0].StatusCode != http.StatusNotFound {
+		t.Fatalf("BrokenPages(): want a single 404, got %v", broken)
+	}
+	if want := []string{server.URL + "/", server.URL + "/a"}; fmt.Sprint(broken[0].Referrers) != fmt.Sprint(want) {
+		t.Errorf("BrokenPages()[0].Referrers: want %v, got %v", want, broken[0].Referrers)
+	}
+
+	redirected := g.RedirectedPages()
+	if len(redirected) != 1 {
+		t.Fatalf("RedirectedPages(): want a single page, got %v", redirected)
+	}
+	if p := redirected[0]; len(p.Redirects) != 2 || p.StatusCode != http.StatusMovedPermanently ||
+		p.FinalURL != server.URL+"/new" || p.FinalStatusCode != http.StatusOK {
+		t.Errorf("RedirectedPages()[0]: want 2 hops ending at %s/new, got %+v", server.URL, p)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hostLimiter spaces out requests to the same host by at least delay, no
+// matter how many workers are fetching pages from it
+type hostLimiter struct {
+	delay time.Duration
+
+	mu     sync.Mutex
+	next   map[string]time.Time
+	delays map[string]time.Duration
+}
+
+func newHostLimiter(delay time.Duration) *hostLimiter {
+	return &hostLimiter{
+		delay:  delay,
+		next:   map[string]time.Time{},
+		delays: map[string]time.Duration{},
+	}
+}
+
+// SetDelay overrides the delay of host, as long as it's longer than the
+// default one (e.g. a robots.txt Crawl-delay)
+func (l *hostLimiter) SetDelay(host string, delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.delays[host] = delay
+}
+
+// Wait blocks until a request to host is allowed, or ctx is done
+func (l *hostLimiter) Wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	delay := l.delay
+	if d := l.delays[host]; d > delay {
+		delay = d
+	}
+	if delay <= 0 {
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	// reserve the slot after ours for the next caller
+	l.next[host] = slot.Add(delay)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(slot.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
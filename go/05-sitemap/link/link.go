@@ -10,25 +10,90 @@ import (
 type Link struct {
 	Text string
 	Href string
+	Rel  string
+}
+
+// NoFollow reports whether the link has a rel="nofollow" attribute
+func (l Link) NoFollow() bool {
+	return hasToken(l.Rel, "nofollow")
+}
+
+// Page holds the links of an HTML page, along with the directives of its
+// <meta name="robots"> tags and the href of its <link rel="canonical">
+type Page struct {
+	Links     []Link
+	NoIndex   bool
+	NoFollow  bool
+	Canonical string
 }
 
 func Parse(r io.Reader) ([]Link, error) {
+	page, err := ParsePage(r)
+	if err != nil {
+		return nil, err
+	}
+	return page.Links, nil
+}
+
+func ParsePage(r io.Reader) (*Page, error) {
 	root, err := html.Parse(r)
 	if err != nil {
 		return nil, err
 	}
 
+	var page Page
+	page.Canonical = findCanonical(root)
+	for _, content := range findRobotsMeta(root) {
+		noIndex, noFollow := RobotsDirectives(content)
+		page.NoIndex = page.NoIndex || noIndex
+		page.NoFollow = page.NoFollow || noFollow
+	}
+
 	as := make(chan *html.Node)
 	go findAnchors(root, as)
-	var links []Link
 	for a := range as {
 		l := Link{
 			Text: extractText(a),
-			Href: extractHref(a),
+			Href: extractAttr(a, "href"),
+			Rel:  extractAttr(a, "rel"),
 		}
-		links = append(links, l)
+		page.Links = append(page.Links, l)
 	}
-	return links, nil
+	return &page, nil
+}
+
+// RobotsDirectives reports whether the content of a <meta name="robots"> tag,
+// or an X-Robots-Tag header, asks not to index the page or follow its links
+func RobotsDirectives(content string) (noIndex, noFollow bool) {
+	none := hasToken(content, "none")
+	return none || hasToken(content, "noindex"), none || hasToken(content, "nofollow")
+}
+
+// findRobotsMeta returns the content of every <meta name="robots"> tag
+func findRobotsMeta(n *html.Node) []string {
+	var contents []string
+	if n.Type == html.ElementNode && n.Data == "meta" &&
+		strings.EqualFold(extractAttr(n, "name"), "robots") {
+		contents = append(contents, extractAttr(n, "content"))
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		contents = append(contents, findRobotsMeta(c)...)
+	}
+	return contents
+}
+
+// findCanonical returns the href of the first <link rel="canonical"> tag
+func findCanonical(n *html.Node) string {
+	if n.Type == html.ElementNode && n.Data == "link" &&
+		hasToken(extractAttr(n, "rel"), "canonical") {
+		return extractAttr(n, "href")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if href := findCanonical(c); href != "" {
+			return href
+		}
+	}
+	return ""
 }
 
 func findAnchors(n *html.Node, as chan *html.Node) {
@@ -58,12 +123,25 @@ func extractText(a *html.Node) string {
 	return strings.TrimSpace(text)
 }
 
-func extractHref(a *html.Node) string {
-	for _, attr := range a.Attr {
-		if attr.Key != "href" {
+func extractAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key != key {
 			continue
 		}
 		return attr.Val
 	}
 	return ""
 }
+
+// hasToken reports whether token is one of the comma or space separated
+// tokens in s
+func hasToken(s, token string) bool {
+	for _, t := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
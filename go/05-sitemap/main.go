@@ -1,147 +1,168 @@
 package main
 
 import (
-	"encoding/xml"
+	"context"
 	"flag"
-	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
+	"os/signal"
 	"strings"
-
-	"github.com/ramin0/live/go/sitemap/link"
+	"syscall"
+	"time"
 )
 
 func main() {
 	flagURL := flag.String("url", "", "The URL to create a sitemap for.")
-	flagDepth := flag.Int("depth", 2, "The depth of the links tree.")
-	flagXMLFilename := flag.String("xml", "sitemap.xml", "The name of the sitemap XML file.")
+	flagDepth := flag.Int("depth", 2, "The depth of the links tree, where the page at -url is at depth 0.")
+	flagXMLFilename := flag.String("xml", "sitemap.xml", "The name of the sitemap XML file (deprecated, use -out).")
+	flagOut := flag.String("out", "", "The name of the sitemap file, defaults to sitemap.<format>.")
+	flagFormat := flag.String("format", formatXML, "The format of the sitemap, one of: xml, txt, html.")
+	flagGzip := flag.Bool("gzip", false, "Whether to gzip the sitemap file(s).")
+	flagSitemapBaseURL := flag.String("sitemap-base-url", "", "The URL the sitemap files are served from, used by the sitemap index. Defaults to -url.")
+	changeFreqRules := &pathRules{validate: validateChangeFreq}
+	flag.Var(changeFreqRules, "changefreq", "A pattern=changefreq rule, e.g. /blog/*=daily. Can be repeated, the longest matching pattern wins.")
+	priorityRules := &pathRules{validate: validatePriority}
+	flag.Var(priorityRules, "priority", "A pattern=priority rule, e.g. /=1.0. Can be repeated, the longest matching pattern wins.")
+	flagConcurrency := flag.Int("concurrency", 4, "The number of pages to fetch concurrently.")
+	flagDelay := flag.Duration("delay", 200*time.Millisecond, "The minimum delay between two requests to the same host.")
+	flagTimeout := flag.Duration("timeout", 10*time.Second, "The timeout of a single request.")
+	flagRetries := flag.Int("retries", 2, "The number of times to retry a failed request.")
+	flagBackoff := flag.Duration("backoff", 500*time.Millisecond, "The initial delay before retrying a failed request, doubled on every retry.")
+	flagBrokenLinks := flag.String("broken-links", "", "The name of a CSV file to report broken (4xx/5xx) links in.")
+	flagRedirects := flag.String("redirects", "", "The name of a CSV file to report redirect chains in.")
+	flagGraph := flag.String("graph", "", "The name of a JSON file to write every crawled page to.")
+	var flagInclude, flagExclude regexpsFlag
+	flag.Var(&flagInclude, "include", "A regular expression urls must match to be crawled. Can be repeated, urls must match at least one.")
+	flag.Var(&flagExclude, "exclude", "A regular expression excluding the urls it matches from the crawl. Can be repeated.")
+	flagSubdomains := flag.Bool("subdomains", false, "Whether to crawl the subdomains of -url's host.")
+	flagScheme := flag.String("scheme", schemeNormalize, "What to do with urls whose scheme differs from -url's: normalize (crawl them with -url's scheme), same (skip them) or any (crawl them as they are).")
+	flagStripParams := flag.String("strip-params", strings.Join(defaultStripParams, ","), "A comma-separated list of query parameters to remove from urls, wildcards (e.g. utm_*) are supported.")
+	flagState := flag.String("state", "", "The name of a BoltDB file to checkpoint the crawl to. Pages of earlier crawls in it are only fetched again if they changed.")
+	flagResume := flag.Bool("resume", false, "Whether to resume the interrupted crawl in -state.")
+	flagUserAgent := flag.String("user-agent", "sitemap/1.0 (+https://github.com/ramin0/live)", "The User-Agent header sent with every request.")
 	flag.Parse()
 
 	if *flagURL == "" {
 		log.Fatal("Missing -url flag")
 	}
-
-	sitemap, err := buildSitemap(*flagURL, *flagDepth)
-	if err != nil {
-		log.Fatalf("Failed to build sitemap for %s: %v", *flagURL, err)
+	if *flagResume && *flagState == "" {
+		log.Fatal("Missing -state flag to resume from")
 	}
 
-	if err := generateSitemap(sitemap, *flagXMLFilename); err != nil {
-		log.Fatalf("Failed to generate sitemap in %s: %v", *flagXMLFilename, err)
+	out := *flagOut
+	if out == "" {
+		out = "sitemap." + *flagFormat
+		if *flagFormat == formatXML {
+			out = *flagXMLFilename
+		}
+	}
+	sitemapBaseURL := *flagSitemapBaseURL
+	if sitemapBaseURL == "" {
+		sitemapBaseURL = *flagURL
 	}
 
-	log.Printf("Generated sitemap with %d link(s) for %s in %s", len(sitemap), *flagURL, *flagXMLFilename)
-}
-
-func buildSitemap(baseURL string, depth int) ([]string, error) {
-	// use a map to ensure uniqueness of parsed urls
-	urlsMap := map[string]bool{}
-
-	urls := []string{baseURL}
-	for d := 0; d < depth; d++ {
-		var newURLs []string
-		for _, url := range urls {
-			subURLs, err := getURLs(url)
-			if err != nil {
-				return nil, err
-			}
-			var uniqueSubURLs []string
-			for _, subURL := range subURLs {
-				// if we parsed this url before, skip it
-				if urlsMap[subURL] {
-					continue
-				}
-				// otherwise, add it to the unique urls map
-				urlsMap[subURL] = true
-				uniqueSubURLs = append(uniqueSubURLs, subURL)
-			}
-			newURLs = append(newURLs, uniqueSubURLs...)
+	var stripParams []string
+	for _, p := range strings.Split(*flagStripParams, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			stripParams = append(stripParams, p)
 		}
-		urls = newURLs
 	}
-	return urls, nil
-}
-
-func getURLs(pageURL string) ([]string, error) {
-	pageURL = strings.TrimSuffix(pageURL, "/")
-
-	// fetch the html page for this url
-	res, err := http.Get(pageURL)
+	scope, err := newScope(*flagURL, scopeOptions{
+		Subdomains:  *flagSubdomains,
+		Scheme:      *flagScheme,
+		Include:     flagInclude,
+		Exclude:     flagExclude,
+		StripParams: stripParams,
+	})
 	if err != nil {
-		return nil, err
+		log.Fatalf("Invalid crawl scope: %v", err)
 	}
-	defer res.Body.Close()
 
-	// parse the page and get all links
-	links, err := link.Parse(res.Body)
-	if err != nil {
-		return nil, err
+	c := newCrawler(crawlerOptions{
+		Scope:       scope,
+		Concurrency: *flagConcurrency,
+		Delay:       *flagDelay,
+		Timeout:     *flagTimeout,
+		Retries:     *flagRetries,
+		Backoff:     *flagBackoff,
+		UserAgent:   *flagUserAgent,
+	})
+
+	var state *crawlState
+	if *flagState != "" {
+		var err error
+		if state, err = openCrawlState(*flagState); err != nil {
+			log.Fatalf("Failed to open crawl state %s: %v", *flagState, err)
+		}
+		defer state.Close()
 	}
 
-	// we only care about the href of the links
-	var urls []string
-	for _, l := range links {
-		urls = append(urls, l.Href)
+	// stop crawling on the first interrupt, so progress can be checkpointed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		log.Print("Interrupted, stopping the crawl...")
+		cancel()
+		signal.Stop(sigs)
+	}()
+
+	g, err := crawl(ctx, c, scope.Base(), *flagDepth, state, *flagResume)
+	if err == context.Canceled {
+		if state != nil {
+			log.Printf("Saved the progress of the crawl in %s, run again with -resume to continue", *flagState)
+			// deferred calls don't run on os.Exit
+			state.Close()
+		}
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("Failed to crawl %s: %v", *flagURL, err)
+	}
+	failed := g.FailedPages()
+	for _, p := range failed {
+		log.Printf("Failed to crawl %s: %s", p.URL, p.Err)
 	}
 
-	// filter out non-domain links
-	var domainURLs []string
-	for _, url := range urls {
-		// http://google.com or https://ramin0.me
-		if strings.HasPrefix(url, "http") &&
-			!strings.HasPrefix(url, pageURL) {
-			continue
-		}
-		// https://domain.com/...
-		if strings.HasPrefix(url, pageURL) {
-			domainURLs = append(domainURLs, url)
-			continue
-		}
-		// mailto:email@example.com
-		if strings.Contains(url, ":") {
+	reports := []struct {
+		path  string
+		write func(*crawlGraph, string) error
+	}{
+		{*flagBrokenLinks, writeBrokenLinksReport},
+		{*flagRedirects, writeRedirectsReport},
+		{*flagGraph, writeGraph},
+	}
+	for _, r := range reports {
+		if r.path == "" {
 			continue
 		}
-		// remove # portion of the url
-		// https://domain.com/path/to/page#some-link
-		if i := strings.Index(url, "#"); i != -1 {
-			url = url[:i]
-		}
-		// prefix with a / if not already there
-		if url == "" || url[0] != '/' {
-			url = "/" + url
+		if err := r.write(g, r.path); err != nil {
+			log.Fatalf("Failed to write report in %s: %v", r.path, err)
 		}
-		// convert /path/to/page to https://domain.com/path/to/page
-		url = pageURL + url
-		domainURLs = append(domainURLs, url)
 	}
-	return domainURLs, nil
-}
 
-// Generated using https://www.onlinetool.io/xmltogo
-type SitemapXML struct {
-	XMLName xml.Name        `xml:"urlset"`
-	Xmlns   string          `xml:"xmlns,attr"`
-	URLs    []SitemapXMLURL `xml:"url"`
-}
-type SitemapXMLURL struct {
-	Loc string `xml:"loc"`
-}
-
-func generateSitemap(urls []string, pathToXML string) error {
-	var sitemap SitemapXML
-	sitemap.Xmlns = "https://www.sitemaps.org/schemas/sitemap/0.9"
-	for _, url := range urls {
-		sitemap.URLs = append(sitemap.URLs, SitemapXMLURL{
-			Loc: url,
+	var entries []sitemapEntry
+	for _, p := range g.SitemapPages() {
+		entries = append(entries, sitemapEntry{
+			Loc:        p.URL,
+			LastMod:    p.LastModified,
+			ChangeFreq: changeFreqRules.Match(p.URL),
+			Priority:   priorityRules.Match(p.URL),
 		})
 	}
 
-	// Alternative: xml.NewEncoder(f).Encode(&sitemap)
-	sitemapBytes, err := xml.MarshalIndent(&sitemap, "", "\t")
+	files, err := generateSitemap(entries, sitemapOptions{
+		Path:    out,
+		Format:  *flagFormat,
+		Gzip:    *flagGzip,
+		BaseURL: sitemapBaseURL,
+	})
 	if err != nil {
-		return err
+		log.Fatalf("Failed to generate sitemap in %s: %v", out, err)
 	}
-	xmlData := []byte(xml.Header + string(sitemapBytes))
-	return ioutil.WriteFile(pathToXML, xmlData, os.ModePerm)
+
+	log.Printf("Generated sitemap with %d link(s) for %s in %s (%d page(s) crawled, %d broken, %d failed)",
+		len(entries), *flagURL, strings.Join(files, ", "), len(g.Order), len(g.BrokenPages()), len(failed))
 }
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// writeBrokenLinksReport writes a CSV row for every broken page, listing the
+// pages linking to it
+func writeBrokenLinksReport(g *crawlGraph, path string) error {
+	var rows [][]string
+	for _, p := range g.BrokenPages() {
+		rows = append(rows, []string{
+			p.URL,
+			strconv.Itoa(p.StatusCode),
+			strconv.Itoa(p.InboundLinks),
+			strings.Join(p.Referrers, " "),
+		})
+	}
+	return writeCSV(path, []string{"url", "status_code", "inbound_links", "referrers"}, rows)
+}
+
+// writeRedirectsReport writes a CSV row for every page that redirected, with
+// its full chain of redirects
+func writeRedirectsReport(g *crawlGraph, path string) error {
+	var rows [][]string
+	for _, p := range g.RedirectedPages() {
+		var chain []string
+		for _, r := range p.Redirects {
+			chain = append(chain, fmt.Sprintf("%s (%d)", r.URL, r.StatusCode))
+		}
+		chain = append(chain, p.FinalURL)
+		rows = append(rows, []string{
+			p.URL,
+			strconv.Itoa(len(p.Redirects)),
+			strings.Join(chain, " -> "),
+			p.FinalURL,
+			strconv.Itoa(p.FinalStatusCode),
+			strings.Join(p.Referrers, " "),
+		})
+	}
+	return writeCSV(path, []string{"url", "hops", "chain", "final_url", "final_status_code", "referrers"}, rows)
+}
+
+// writeGraph writes every page of the crawl as JSON, in discovery order
+func writeGraph(g *crawlGraph, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	pages := make([]*pageInfo, 0, len(g.Order))
+	for _, url := range g.Order {
+		pages = append(pages, g.Pages[url])
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(pages); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func writeCSV(path string, header []string, rows [][]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robots holds the rules of a robots.txt file that apply to a single
+// user-agent
+type robots struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsAllowAll and robotsDisallowAll are used when a robots.txt file is
+// missing or can't be fetched
+var (
+	robotsAllowAll    = &robots{}
+	robotsDisallowAll = &robots{rules: []robotsRule{{allow: false, pattern: "/"}}}
+)
+
+type robotsGroup struct {
+	userAgents []string
+	robots
+}
+
+// parseRobots parses a robots.txt file and keeps the rules of the group that
+// best matches userAgent. Groups naming the same user-agent are merged, and
+// the "*" group is only used if no other group matches.
+func parseRobots(r io.Reader, userAgent string) (*robots, error) {
+	var groups []*robotsGroup
+	var group *robotsGroup
+	// consecutive user-agent lines belong to the same group
+	inUserAgents := false
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		i := strings.Index(line, ":")
+		if i == -1 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:i]))
+		value := strings.TrimSpace(line[i+1:])
+
+		switch key {
+		case "user-agent":
+			if !inUserAgents {
+				group = &robotsGroup{}
+				groups = append(groups, group)
+				inUserAgents = true
+			}
+			// an empty user-agent names no crawler at all
+			if value != "" {
+				group.userAgents = append(group.userAgents, strings.ToLower(value))
+			}
+			continue
+		case "allow", "disallow":
+			// an empty Disallow means everything is allowed
+			if group != nil && value != "" {
+				group.rules = append(group.rules, robotsRule{
+					allow:   key == "allow",
+					pattern: value,
+				})
+			}
+		case "crawl-delay":
+			if secs, err := strconv.ParseFloat(value, 64); group != nil && err == nil && secs > 0 {
+				group.crawlDelay = time.Duration(secs * float64(time.Second))
+			}
+		}
+		inUserAgents = false
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	token := userAgentToken(userAgent)
+	var best []*robotsGroup
+	var bestLen int
+	var wildcard []*robotsGroup
+	for _, g := range groups {
+		for _, ua := range g.userAgents {
+			switch {
+			case ua == "*":
+				wildcard = append(wildcard, g)
+			case strings.HasPrefix(token, ua) && len(ua) > bestLen:
+				best, bestLen = []*robotsGroup{g}, len(ua)
+			case strings.HasPrefix(token, ua) && len(ua) == bestLen:
+				best = append(best, g)
+			}
+		}
+	}
+	if best == nil {
+		best = wildcard
+	}
+
+	var rbts robots
+	for _, g := range best {
+		rbts.rules = append(rbts.rules, g.rules...)
+		if g.crawlDelay > rbts.crawlDelay {
+			rbts.crawlDelay = g.crawlDelay
+		}
+	}
+	return &rbts, nil
+}
+
+// userAgentToken extracts the product token robots.txt groups are matched
+// against, e.g. "sitemap" from "sitemap/1.0 (+https://github.com/ramin0/live)"
+func userAgentToken(userAgent string) string {
+	token := strings.ToLower(userAgent)
+	if i := strings.IndexAny(token, "/ "); i != -1 {
+		token = token[:i]
+	}
+	return token
+}
+
+// Allowed reports whether u can be crawled. The longest matching rule wins,
+// and Allow wins over Disallow when both match with the same length.
+func (r *robots) Allowed(u *url.URL) bool {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	allowed, matchLen := true, -1
+	for _, rule := range r.rules {
+		if !matchRobotsPattern(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > matchLen || n == matchLen && rule.allow {
+			allowed, matchLen = rule.allow, n
+		}
+	}
+	return allowed
+}
+
+// matchRobotsPattern matches path against a robots.txt path pattern, where
+// "*" matches any sequence of characters and a trailing "$" anchors the
+// pattern to the end of the path
+func matchRobotsPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	path = path[len(parts[0]):]
+	parts = parts[1:]
+	if len(parts) == 0 {
+		return !anchored || path == ""
+	}
+
+	for i, part := range parts {
+		last := i == len(parts)-1
+		if last && anchored {
+			return strings.HasSuffix(path, part)
+		}
+		j := strings.Index(path, part)
+		if j == -1 {
+			return false
+		}
+		path = path[j+len(part):]
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRobotsTxt = `
+# comments are ignored
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: sitemap
+User-agent: otherbot
+Disallow: /no-sitemap/
+Allow: /no-sitemap/but-this
+Crawl-delay: 0.5
+
+User-agent: sitemap-extended
+Disallow: /
+
+# an empty user-agent matches no crawler
+User-agent:
+Disallow: /
+`
+
+func TestParseRobots(t *testing.T) {
+	cases := []struct {
+		name       string
+		userAgent  string
+		crawlDelay time.Duration
+		allowed    map[string]bool
+	}{
+		{
+			name:       "wildcard group",
+			userAgent:  "somebot/2.0",
+			crawlDelay: 2 * time.Second,
+			allowed: map[string]bool{
+				"/":                     true,
+				"/private":              false,
+				"/private/secret":       false,
+				"/private/public":       true,
+				"/private/public/page":  true,
+				"/files/report.pdf":     false,
+				"/files/report.pdf?x=1": true,
+				"/robots.txt":           true,
+				"/no-sitemap/":          true,
+			},
+		},
+		{
+			name:       "specific group",
+			userAgent:  "sitemap/1.0 (+https://github.com/ramin0/live)",
+			crawlDelay: 500 * time.Millisecond,
+			allowed: map[string]bool{
+				"/private":             true,
+				"/files/report.pdf":    true,
+				"/no-sitemap/":         false,
+				"/no-sitemap/page":     false,
+				"/no-sitemap/but-this": true,
+			},
+		},
+		{
+			name:      "longest user-agent wins",
+			userAgent: "sitemap-extended",
+			allowed: map[string]bool{
+				"/":            false,
+				"/robots.txt":  true,
+				"/no-sitemap/": false,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, err := parseRobots(strings.NewReader(testRobotsTxt), c.userAgent)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			if r.crawlDelay != c.crawlDelay {
+				t.Errorf("crawlDelay: want %v, got %v", c.crawlDelay, r.crawlDelay)
+			}
+			for path, allowed := range c.allowed {
+				u, err := url.Parse("https://example.com" + path)
+				if err != nil {
+					t.Fatalf("failed to parse url: %v", err)
+				}
+				if got := r.Allowed(u); got != allowed {
+					t.Errorf("Allowed(%q): want %v, got %v", path, allowed, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchRobotsPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/", "/anything", true},
+		{"/fish", "/fish.html", true},
+		{"/fish", "/Fish.html", false},
+		{"/fish*", "/fishheads/yummy.html", true},
+		{"/*.php", "/folder/filename.php?parameters", true},
+		{"/*.php$", "/filename.php", true},
+		{"/*.php$", "/filename.php?parameters", false},
+		{"/fish*.php", "/fishheads/catfish.php?parameters", true},
+		{"/fish*.php", "/Fish.PHP", false},
+		{"/a$", "/a", true},
+		{"/a$", "/ab", false},
+	}
+
+	for _, c := range cases {
+		if got := matchRobotsPattern(c.pattern, c.path); got != c.match {
+			t.Errorf("matchRobotsPattern(%q, %q): want %v, got %v", c.pattern, c.path, c.match, got)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// pathRule assigns a value to the urls whose path matches pattern, using the
+// same syntax as robots.txt paths ("*" wildcards and a trailing "$")
+type pathRule struct {
+	pattern string
+	value   string
+}
+
+// pathRules is a flag.Value collecting "pattern=value" rules, where the
+// longest matching pattern wins
+type pathRules struct {
+	rules    []pathRule
+	validate func(string) error
+}
+
+func (r *pathRules) String() string {
+	if r == nil {
+		return ""
+	}
+	var s []string
+	for _, rule := range r.rules {
+		s = append(s, rule.pattern+"="+rule.value)
+	}
+	return strings.Join(s, ",")
+}
+
+func (r *pathRules) Set(s string) error {
+	i := strings.LastIndex(s, "=")
+	if i == -1 {
+		return fmt.Errorf("expected pattern=value, got %q", s)
+	}
+	pattern, value := s[:i], s[i+1:]
+	if !strings.HasPrefix(pattern, "/") {
+		pattern = "/" + pattern
+	}
+	if r.validate != nil {
+		if err := r.validate(value); err != nil {
+			return err
+		}
+	}
+	r.rules = append(r.rules, pathRule{pattern: pattern, value: value})
+	return nil
+}
+
+// Match returns the value of the longest rule matching the path of rawURL
+func (r *pathRules) Match(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	var value string
+	matchLen := -1
+	for _, rule := range r.rules {
+		if len(rule.pattern) > matchLen && matchRobotsPattern(rule.pattern, path) {
+			value, matchLen = rule.value, len(rule.pattern)
+		}
+	}
+	return value
+}
+
+func validateChangeFreq(s string) error {
+	switch s {
+	case "always", "hourly", "daily", "weekly", "monthly", "yearly", "never":
+		return nil
+	}
+	return fmt.Errorf("invalid changefreq %q", s)
+}
+
+func validatePriority(s string) error {
+	p, err := strconv.ParseFloat(s, 64)
+	if err != nil || p < 0 || p > 1 {
+		return fmt.Errorf("invalid priority %q, must be between 0.0 and 1.0", s)
+	}
+	return nil
+}
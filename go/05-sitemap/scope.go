@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// The list of policies for urls whose scheme differs from the base url's
+const (
+	// schemeNormalize keeps both http and https urls in scope, but rewrites
+	// them to the base url's scheme
+	schemeNormalize = "normalize"
+	// schemeSame only keeps urls with the base url's scheme in scope
+	schemeSame = "same"
+	// schemeAny keeps both http and https urls in scope, as they are
+	schemeAny = "any"
+)
+
+// defaultStripParams are the query parameters stripped from every url unless
+// told otherwise, since they only track where visitors come from
+var defaultStripParams = []string{"utm_*", "fbclid", "gclid", "mc_cid", "mc_eid"}
+
+// scope decides which urls belong to the crawl of a site, and turns them into
+// a canonical form so the same page is never crawled twice under different
+// urls
+type scope struct {
+	base *url.URL
+	opts scopeOptions
+}
+
+type scopeOptions struct {
+	// Subdomains keeps the subdomains of the base url's host in scope
+	Subdomains bool
+	// Scheme is one of schemeNormalize, schemeSame or schemeAny
+	Scheme string
+	// Include, if not empty, only keeps the urls matching one of them in
+	// scope, while Exclude leaves the urls matching any of them out
+	Include []*regexp.Regexp
+	Exclude []*regexp.Regexp
+	// StripParams are the query parameters to remove, as path.Match patterns
+	StripParams []string
+}
+
+func newScope(baseURL string, opts scopeOptions) (*scope, error) {
+	if opts.Scheme == "" {
+		opts.Scheme = schemeNormalize
+	}
+	switch opts.Scheme {
+	case schemeNormalize, schemeSame, schemeAny:
+	default:
+		return nil, fmt.Errorf("unknown scheme policy %q", opts.Scheme)
+	}
+	for _, p := range opts.StripParams {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid query parameter pattern %q: %v", p, err)
+		}
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported url %q, expected an http(s) url", baseURL)
+	}
+
+	s := &scope{opts: opts}
+	s.base = s.canonicalize(u)
+	return s, nil
+}
+
+// Base returns the canonical form of the base url
+func (s *scope) Base() string {
+	return s.base.String()
+}
+
+// Resolve resolves href relative to the page it's on, and returns its
+// canonical form if it's in scope
+func (s *scope) Resolve(pageURL, href string) (string, bool) {
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return "", false
+	}
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return "", false
+	}
+	u := page.ResolveReference(ref)
+	if !s.inScope(u) {
+		return "", false
+	}
+	return s.canonicalize(u).String(), true
+}
+
+func (s *scope) inScope(u *url.URL) bool {
+	switch u.Scheme {
+	case "http", "https":
+	default:
+		// mailto:, javascript:, tel:, ...
+		return false
+	}
+	if s.opts.Scheme == schemeSame && u.Scheme != s.base.Scheme {
+		return false
+	}
+
+	host, baseHost := siteHost(u), siteHost(s.base)
+	if host != baseHost && !(s.opts.Subdomains && strings.HasSuffix(host, "."+baseHost)) {
+		return false
+	}
+
+	canonical := s.canonicalize(u).String()
+	for _, re := range s.opts.Exclude {
+		if re.MatchString(canonical) {
+			return false
+		}
+	}
+	if len(s.opts.Include) == 0 {
+		return true
+	}
+	for _, re := range s.opts.Include {
+		if re.MatchString(canonical) {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalize lowercases the scheme and host, drops default ports, www
+// variations of the base host, fragments and stripped query parameters, and
+// sorts the remaining ones
+func (s *scope) canonicalize(u *url.URL) *url.URL {
+	c := *u
+	c.Scheme = strings.ToLower(c.Scheme)
+	c.Host = strings.ToLower(c.Host)
+	c.Fragment = ""
+	c.User = nil
+
+	if host, port, err := net.SplitHostPort(c.Host); err == nil &&
+		(c.Scheme == "http" && port == "80" || c.Scheme == "https" && port == "443") {
+		c.Host = host
+	}
+
+	if s.base != nil {
+		if s.opts.Scheme == schemeNormalize {
+			c.Scheme = s.base.Scheme
+		}
+		// www.example.com and example.com are the same site
+		if siteHost(&c) == siteHost(s.base) {
+			c.Host = s.base.Host
+		}
+	}
+
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	c.RawPath = ""
+
+	query := c.Query()
+	for key := range query {
+		for _, p := range s.opts.StripParams {
+			if ok, _ := path.Match(p, key); ok {
+				query.Del(key)
+				break
+			}
+		}
+	}
+	// Encode sorts the parameters by key
+	c.RawQuery = query.Encode()
+	c.ForceQuery = false
+
+	return &c
+}
+
+// siteHost returns the host of u without its default port and "www." prefix
+func siteHost(u *url.URL) string {
+	host := strings.ToLower(u.Host)
+	if h, port, err := net.SplitHostPort(host); err == nil &&
+		(u.Scheme == "http" && port == "80" || u.Scheme == "https" && port == "443") {
+		host = h
+	}
+	return strings.TrimPrefix(host, "www.")
+}
+
+// regexpsFlag is a flag.Value collecting regular expressions
+type regexpsFlag []*regexp.Regexp
+
+func (f *regexpsFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	var s []string
+	for _, re := range *f {
+		s = append(s, re.String())
+	}
+	return strings.Join(s, ",")
+}
+
+func (f *regexpsFlag) Set(s string) error {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, re)
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestScope_Resolve(t *testing.T) {
+	cases := []struct {
+		name string
+		opts scopeOptions
+		page string
+		href string
+		url  string
+		ok   bool
+	}{
+		{
+			name: "relative to the page",
+			page: "https://example.com/blog/",
+			href: "post?b=2&a=1#comments",
+			url:  "https://example.com/blog/post?a=1&b=2",
+			ok:   true,
+		},
+		{
+			name: "sibling path",
+			page: "https://example.com/blog/post",
+			href: "/about",
+			url:  "https://example.com/about",
+			ok:   true,
+		},
+		{
+			name: "www and scheme variations",
+			page: "https://example.com/",
+			href: "http://WWW.example.com:80/about",
+			url:  "https://example.com/about",
+			ok:   true,
+		},
+		{
+			name: "same scheme only",
+			opts: scopeOptions{Scheme: schemeSame},
+			page: "https://example.com/",
+			href: "http://example.com/about",
+		},
+		{
+			name: "other site",
+			page: "https://example.com/",
+			href: "https://example.org/",
+		},
+		{
+			name: "subdomain",
+			page: "https://example.com/",
+			href: "https://blog.example.com/",
+		},
+		{
+			name: "subdomain allowed",
+			opts: scopeOptions{Subdomains: true},
+			page: "https://example.com/",
+			href: "https://blog.example.com",
+			url:  "https://blog.example.com/",
+			ok:   true,
+		},
+		{
+			name: "not http",
+			page: "https://example.com/",
+			href: "mailto:someone@example.com",
+		},
+		{
+			name: "tracking params",
+			opts: scopeOptions{StripParams: defaultStripParams},
+			page: "https://example.com/",
+			href: "/?utm_source=newsletter&utm_medium=email&page=2",
+			url:  "https://example.com/?page=2",
+			ok:   true,
+		},
+		{
+			name: "excluded",
+			opts: scopeOptions{Exclude: []*regexp.Regexp{regexp.MustCompile(`/tags/`)}},
+			page: "https://example.com/",
+			href: "/tags/go",
+		},
+		{
+			name: "not included",
+			opts: scopeOptions{Include: []*regexp.Regexp{regexp.MustCompile(`^https://example\.com/docs/`)}},
+			page: "https://example.com/",
+			href: "/blog/",
+		},
+		{
+			name: "included",
+			opts: scopeOptions{Include: []*regexp.Regexp{regexp.MustCompile(`^https://example\.com/docs/`)}},
+			page: "https://example.com/",
+			href: "/docs/intro",
+			url:  "https://example.com/docs/intro",
+			ok:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := newScope("https://example.com", c.opts)
+			if err != nil {
+				t.Fatalf("failed to create scope: %v", err)
+			}
+			url, ok := s.Resolve(c.page, c.href)
+			if ok != c.ok || url != c.url {
+				t.Errorf("Resolve(%q, %q): want %q, %v, got %q, %v", c.page, c.href, c.url, c.ok, url, ok)
+			}
+		})
+	}
+}
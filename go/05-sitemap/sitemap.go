@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The limits of a single sitemap file, as defined by the sitemap protocol
+// (https://www.sitemaps.org/protocol.html)
+const (
+	sitemapMaxURLs  = 50000
+	sitemapMaxBytes = 50 * 1024 * 1024
+)
+
+// The list of supported sitemap formats
+const (
+	formatXML  = "xml"
+	formatText = "txt"
+	formatHTML = "html"
+)
+
+const sitemapXmlns = "https://www.sitemaps.org/schemas/sitemap/0.9"
+
+// sitemapEntry holds everything a sitemap knows about a single url
+type sitemapEntry struct {
+	Loc        string
+	LastMod    time.Time
+	ChangeFreq string
+	Priority   string
+}
+
+// Generated using https://www.onlinetool.io/xmltogo
+//
+// The <urlset> itself is written by hand, so the size of every <url> can be
+// counted while splitting the sitemap.
+type SitemapXMLURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type SitemapIndexXML struct {
+	XMLName  xml.Name                 `xml:"sitemapindex"`
+	Xmlns    string                   `xml:"xmlns,attr"`
+	Sitemaps []SitemapIndexXMLSitemap `xml:"sitemap"`
+}
+type SitemapIndexXMLSitemap struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapOptions struct {
+	// Path is the file the sitemap (or the sitemap index) is written to
+	Path   string
+	Format string
+	Gzip   bool
+	// BaseURL is where the sitemap files are served from, used to link them
+	// from a sitemap index
+	BaseURL string
+
+	// MaxURLs and MaxBytes default to the protocol limits
+	MaxURLs  int
+	MaxBytes int
+}
+
+// generateSitemap writes the sitemap for entries and returns the paths of the
+// written files. XML sitemaps exceeding the protocol limits are split into
+// several files tied together by a sitemap index written to opts.Path.
+func generateSitemap(entries []sitemapEntry, opts sitemapOptions) ([]string, error) {
+	if opts.MaxURLs <= 0 {
+		opts.MaxURLs = sitemapMaxURLs
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = sitemapMaxBytes
+	}
+
+	switch opts.Format {
+	case formatXML:
+		return generateXMLSitemap(entries, opts)
+	case formatText:
+		return generateTextSitemap(entries, opts)
+	case formatHTML:
+		return generateHTMLSitemap(entries, opts)
+	}
+	return nil, fmt.Errorf("unknown sitemap format %q", opts.Format)
+}
+
+func generateXMLSitemap(entries []sitemapEntry, opts sitemapOptions) ([]string, error) {
+	header := []byte(xml.Header + `<urlset xmlns="` + sitemapXmlns + `">` + "\n")
+	footer := []byte("</urlset>\n")
+
+	var chunks [][]byte
+	for _, e := range entries {
+		u := SitemapXMLURL{
+			Loc:        e.Loc,
+			ChangeFreq: e.ChangeFreq,
+			Priority:   e.Priority,
+		}
+		if !e.LastMod.IsZero() {
+			u.LastMod = e.LastMod.UTC().Format(time.RFC3339)
+		}
+		var buf bytes.Buffer
+		enc := xml.NewEncoder(&buf)
+		enc.Indent("\t", "\t")
+		if err := enc.EncodeElement(&u, xml.StartElement{Name: xml.Name{Local: "url"}}); err != nil {
+			return nil, err
+		}
+		if err := enc.Flush(); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		chunks = append(chunks, buf.Bytes())
+	}
+
+	files := splitChunks(chunks, len(header)+len(footer), opts)
+	if len(files) <= 1 {
+		var body []byte
+		if len(files) == 1 {
+			body = bytes.Join(files[0], nil)
+		}
+		data := append(append(header, body...), footer...)
+		path, err := writeSitemapFile(opts.Path, data, opts.Gzip)
+		if err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	}
+
+	if opts.BaseURL == "" {
+		return nil, fmt.Errorf("a base url is required to split the sitemap into %d files", len(files))
+	}
+
+	index := SitemapIndexXML{Xmlns: sitemapXmlns}
+	var paths []string
+	now := time.Now().UTC().Format(time.RFC3339)
+	for i, file := range files {
+		data := append(append(append([]byte{}, header...), bytes.Join(file, nil)...), footer...)
+		path, err := writeSitemapFile(numberedPath(opts.Path, i+1), data, opts.Gzip)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+
+		loc, err := url.Parse(strings.TrimSuffix(opts.BaseURL, "/") + "/" + filepath.Base(path))
+		if err != nil {
+			return nil, err
+		}
+		index.Sitemaps = append(index.Sitemaps, SitemapIndexXMLSitemap{
+			Loc:     loc.String(),
+			LastMod: now,
+		})
+	}
+
+	indexBytes, err := xml.MarshalIndent(&index, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	path, err := writeSitemapFile(opts.Path, []byte(xml.Header+string(indexBytes)), opts.Gzip)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{path}, paths...), nil
+}
+
+// generateTextSitemap writes a url per line. Text sitemaps can't be tied
+// together by an index, so they're split into numbered files that each need
+// to be submitted on their own.
+func generateTextSitemap(entries []sitemapEntry, opts sitemapOptions) ([]string, error) {
+	var chunks [][]byte
+	for _, e := range entries {
+		chunks = append(chunks, []byte(e.Loc+"\n"))
+	}
+
+	files := splitChunks(chunks, 0, opts)
+	if len(files) <= 1 {
+		var data []byte
+		if len(files) == 1 {
+			data = bytes.Join(files[0], nil)
+		}
+		path, err := writeSitemapFile(opts.Path, data, opts.Gzip)
+		if err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	}
+
+	var paths []string
+	for i, file := range files {
+		path, err := writeSitemapFile(numberedPath(opts.Path, i+1), bytes.Join(file, nil), opts.Gzip)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+var htmlSitemapTemplate = template.Must(template.New("sitemap").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Sitemap</title>
+</head>
+<body>
+  <h1>Sitemap</h1>
+  <ul>
+  {{- range .}}
+    <li><a href="{{.Loc}}">{{.Loc}}</a>{{if not .LastMod.IsZero}} <small>{{.LastMod.Format "2006-01-02"}}</small>{{end}}</li>
+  {{- end}}
+  </ul>
+</body>
+</html>
+`))
+
+// generateHTMLSitemap writes a sitemap meant for humans, so it's never split
+func generateHTMLSitemap(entries []sitemapEntry, opts sitemapOptions) ([]string, error) {
+	var buf bytes.Buffer
+	if err := htmlSitemapTemplate.Execute(&buf, entries); err != nil {
+		return nil, err
+	}
+	path, err := writeSitemapFile(opts.Path, buf.Bytes(), opts.Gzip)
+	if err != nil {
+		return nil, err
+	}
+	return []string{path}, nil
+}
+
+// splitChunks groups chunks into files of at most opts.MaxURLs chunks and
+// opts.MaxBytes bytes (including overhead bytes per file)
+func splitChunks(chunks [][]byte, overhead int, opts sitemapOptions) [][][]byte {
+	var files [][][]byte
+	var file [][]byte
+	size := overhead
+	for _, c := range chunks {
+		if len(file) > 0 && (len(file) >= opts.MaxURLs || size+len(c) > opts.MaxBytes) {
+			files = append(files, file)
+			file, size = nil, overhead
+		}
+		file = append(file, c)
+		size += len(c)
+	}
+	if len(file) > 0 {
+		files = append(files, file)
+	}
+	return files
+}
+
+// numberedPath turns sitemap.xml into sitemap-n.xml (and sitemap.xml.gz into
+// sitemap-n.xml.gz)
+func numberedPath(path string, n int) string {
+	var gz string
+	if strings.HasSuffix(path, ".gz") {
+		path, gz = strings.TrimSuffix(path, ".gz"), ".gz"
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%d%s%s", strings.TrimSuffix(path, ext), n, ext, gz)
+}
+
+// writeSitemapFile writes data to path, gzipping it (and adding a .gz
+// extension to path) if needed, and returns the path it was written to
+func writeSitemapFile(path string, data []byte, gz bool) (string, error) {
+	if gz {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return "", err
+		}
+		if err := w.Close(); err != nil {
+			return "", err
+		}
+		data = buf.Bytes()
+		if !strings.HasSuffix(path, ".gz") {
+			path += ".gz"
+		}
+	}
+	return path, ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGenerateSitemap_split(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sitemap")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var entries []sitemapEntry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, sitemapEntry{
+			Loc:        fmt.Sprintf("https://example.com/%d", i),
+			LastMod:    time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+			ChangeFreq: "daily",
+			Priority:   "0.5",
+		})
+	}
+
+	files, err := generateSitemap(entries, sitemapOptions{
+		Path:    filepath.Join(dir, "sitemap.xml"),
+		Format:  formatXML,
+		BaseURL: "https://example.com/sitemaps/",
+		MaxURLs: 2,
+	})
+	if err != nil {
+		t.Fatalf("generateSitemap() received an error: %v", err)
+	}
+	if len(files) != 4 {
+		t.Fatalf("len(files): want %d (index + 3 sitemaps), got %d", 4, len(files))
+	}
+
+	b, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+	var index SitemapIndexXML
+	if err := xml.Unmarshal(b, &index); err != nil {
+		t.Fatalf("failed to parse index: %v", err)
+	}
+	if len(index.Sitemaps) != 3 {
+		t.Fatalf("len(index.Sitemaps): want %d, got %d", 3, len(index.Sitemaps))
+	}
+	if want := "https://example.com/sitemaps/sitemap-1.xml"; index.Sitemaps[0].Loc != want {
+		t.Errorf("index.Sitemaps[0].Loc: want %s, got %s", want, index.Sitemaps[0].Loc)
+	}
+
+	b, err = ioutil.ReadFile(files[1])
+	if err != nil {
+		t.Fatalf("failed to read sitemap: %v", err)
+	}
+	var urlset struct {
+		URLs []SitemapXMLURL `xml:"url"`
+	}
+	if err := xml.Unmarshal(b, &urlset); err != nil {
+		t.Fatalf("failed to parse sitemap: %v", err)
+	}
+	want := SitemapXMLURL{
+		Loc:        "https://example.com/0",
+		LastMod:    "2020-05-01T00:00:00Z",
+		ChangeFreq: "daily",
+		Priority:   "0.5",
+	}
+	if len(urlset.URLs) != 2 || urlset.URLs[0] != want {
+		t.Errorf("urlset.URLs: want 2 urls starting with %+v, got %+v", want, urlset.URLs)
+	}
+}
+
+func TestSplitChunks(t *testing.T) {
+	chunks := [][]byte{[]byte("aaaa"), []byte("bbbb"), []byte("cccc")}
+
+	files := splitChunks(chunks, 3, sitemapOptions{MaxURLs: 10, MaxBytes: 10})
+	if len(files) != 3 {
+		t.Errorf("len(files): want %d when splitting by size, got %d", 3, len(files))
+	}
+
+	files = splitChunks(chunks, 0, sitemapOptions{MaxURLs: 10, MaxBytes: 10})
+	if len(files) != 2 {
+		t.Errorf("len(files): want %d when splitting by size, got %d", 2, len(files))
+	}
+
+	files = splitChunks(chunks, 0, sitemapOptions{MaxURLs: 1, MaxBytes: 100})
+	if len(files) != 3 {
+		t.Errorf("len(files): want %d when splitting by count, got %d", 3, len(files))
+	}
+}
+
+func TestPathRules_Match(t *testing.T) {
+	r := &pathRules{validate: validateChangeFreq}
+	for _, rule := range []string{"/=monthly", "/blog/*=daily", "/blog/archive=yearly"} {
+		if err := r.Set(rule); err != nil {
+			t.Fatalf("Set(%q) received an error: %v", rule, err)
+		}
+	}
+	if err := r.Set("/=sometimes"); err == nil {
+		t.Errorf("Set(%q): expected an error", "/=sometimes")
+	}
+
+	cases := map[string]string{
+		"https://example.com":                   "monthly",
+		"https://example.com/about":             "monthly",
+		"https://example.com/blog/post":         "daily",
+		"https://example.com/blog/archive/2020": "yearly",
+	}
+	for url, want := range cases {
+		if got := r.Match(url); got != want {
+			t.Errorf("Match(%q): want %s, got %s", url, want, got)
+		}
+	}
+}
+
+func TestNumberedPath(t *testing.T) {
+	cases := map[string]string{
+		"sitemap.xml":        "sitemap-2.xml",
+		"out/sitemap.xml.gz": "out/sitemap-2.xml.gz",
+		"sitemap":            "sitemap-2",
+	}
+	for path, want := range cases {
+		if got := numberedPath(path, 2); got != want {
+			t.Errorf("numberedPath(%q): want %s, got %s", path, want, got)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// metaBucketName holds what the crawl in pagesBucketName is about
+	metaBucketName = []byte("meta")
+	// pagesBucketName holds the pages of the current crawl by url, and
+	// orderBucketName holds their urls in discovery order
+	pagesBucketName = []byte("pages")
+	orderBucketName = []byte("order")
+	// frontierBucketName holds the urls that still need to be fetched
+	frontierBucketName = []byte("frontier")
+	// previousBucketName holds the pages of earlier crawls, used to make
+	// conditional requests
+	previousBucketName = []byte("previous")
+
+	metaBaseURLKey = []byte("base_url")
+	metaDepthKey   = []byte("depth")
+	metaDoneKey    = []byte("done")
+)
+
+// crawlState persists the progress of a crawl in a BoltDB file
+type crawlState struct {
+	db *bolt.DB
+}
+
+func openCrawlState(path string) (*crawlState, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{
+			metaBucketName, pagesBucketName, orderBucketName,
+			frontierBucketName, previousBucketName,
+		} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &crawlState{db: db}, nil
+}
+
+func (s *crawlState) Close() error {
+	return s.db.Close()
+}
+
+// Start returns the graph and queue to start crawling from, along with the
+// pages of earlier crawls. When resuming, they're those of the interrupted
+// crawl. Otherwise, the pages of the last crawl are moved to the earlier
+// crawls, and a new crawl of baseURL starts.
+func (s *crawlState) Start(baseURL string, depth int, resume bool) (*crawlGraph, []string, map[string]*pageInfo, error) {
+	g := newCrawlGraph()
+	var queue []string
+	cache := map[string]*pageInfo{}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(metaBucketName)
+		pages := tx.Bucket(pagesBucketName)
+
+		if err := tx.Bucket(previousBucketName).ForEach(func(k, v []byte) error {
+			var p pageInfo
+			if err := json.Unmarshal(v, &p); err != nil {
+				return err
+			}
+			cache[string(k)] = &p
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if resume {
+			if meta.Get(metaBaseURLKey) == nil {
+				return fmt.Errorf("no crawl to resume")
+			}
+			if string(meta.Get(metaDoneKey)) == "true" {
+				return fmt.Errorf("the last crawl of %s is already done", meta.Get(metaBaseURLKey))
+			}
+			if got := string(meta.Get(metaBaseURLKey)); got != baseURL {
+				return fmt.Errorf("the crawl to resume is for %s, not %s", got, baseURL)
+			}
+			if got := string(meta.Get(metaDepthKey)); got != strconv.Itoa(depth) {
+				return fmt.Errorf("the crawl to resume has a depth of %s, not %d", got, depth)
+			}
+
+			frontier := tx.Bucket(frontierBucketName)
+			if err := tx.Bucket(orderBucketName).ForEach(func(_, url []byte) error {
+				var p pageInfo
+				if err := json.Unmarshal(pages.Get(url), &p); err != nil {
+					return err
+				}
+				g.Pages[p.URL] = &p
+				g.Order = append(g.Order, p.URL)
+				if frontier.Get(url) != nil {
+					queue = append(queue, p.URL)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			// everything loaded is already saved
+			g.saved = len(g.Order)
+			return nil
+		}
+
+		// keep the pages of the last crawl around for conditional requests
+		previous := tx.Bucket(previousBucketName)
+		if err := pages.ForEach(func(k, v []byte) error {
+			var p pageInfo
+			if err := json.Unmarshal(v, &p); err != nil {
+				return err
+			}
+			// pages that were never fetched have nothing to compare against
+			if p.StatusCode == 0 {
+				return nil
+			}
+			cache[string(k)] = &p
+			return previous.Put(k, v)
+		}); err != nil {
+			return err
+		}
+		for _, name := range [][]byte{pagesBucketName, orderBucketName, frontierBucketName} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+
+		if err := meta.Put(metaBaseURLKey, []byte(baseURL)); err != nil {
+			return err
+		}
+		if err := meta.Put(metaDepthKey, []byte(strconv.Itoa(depth))); err != nil {
+			return err
+		}
+		if err := meta.Put(metaDoneKey, []byte("false")); err != nil {
+			return err
+		}
+
+		g.add(baseURL, 0)
+		queue = []string{baseURL}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return g, queue, cache, nil
+}
+
+// Checkpoint saves the pages that changed since the last checkpoint, and
+// replaces the frontier with queue
+func (s *crawlState) Checkpoint(g *crawlGraph, queue []string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		pages := tx.Bucket(pagesBucketName)
+		for url := range g.dirty {
+			b, err := json.Marshal(g.Pages[url])
+			if err != nil {
+				return err
+			}
+			if err := pages.Put([]byte(url), b); err != nil {
+				return err
+			}
+		}
+
+		order := tx.Bucket(orderBucketName)
+		for _, url := range g.Order[g.saved:] {
+			seq, err := order.NextSequence()
+			if err != nil {
+				return err
+			}
+			if err := order.Put(itob(seq), []byte(url)); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.DeleteBucket(frontierBucketName); err != nil {
+			return err
+		}
+		frontier, err := tx.CreateBucket(frontierBucketName)
+		if err != nil {
+			return err
+		}
+		for _, url := range queue {
+			if err := frontier.Put([]byte(url), []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	g.dirty = map[string]bool{}
+	g.saved = len(g.Order)
+	return nil
+}
+
+// Finish marks the current crawl as done, so it can't be resumed
+func (s *crawlState) Finish() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucketName).Put(metaDoneKey, []byte("true"))
+	})
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
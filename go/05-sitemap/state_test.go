@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCrawlState_resume(t *testing.T) {
+	state, teardown := openTestCrawlState(t)
+	defer teardown()
+
+	g, queue, _, err := state.Start("https://example.com", 1, false)
+	if err != nil {
+		t.Fatalf("Start() received an error: %v", err)
+	}
+	base := g.Pages["https://example.com"]
+	base.StatusCode = http.StatusOK
+	g.dirty[base.URL] = true
+	for _, url := range []string{"https://example.com/a", "https://example.com/b"} {
+		sub, _ := g.add(url, 1)
+		g.link(base, sub)
+	}
+	queue = []string{"https://example.com/b"}
+	if err := state.Checkpoint(g, queue); err != nil {
+		t.Fatalf("Checkpoint() received an error: %v", err)
+	}
+
+	if _, _, _, err := state.Start("https://other.com", 1, true); err == nil {
+		t.Errorf("Start(): expected an error resuming the crawl of another url")
+	}
+
+	g, queue, _, err = state.Start("https://example.com", 1, true)
+	if err != nil {
+		t.Fatalf("Start() received an error resuming: %v", err)
+	}
+	if want := "[https://example.com https://example.com/a https://example.com/b]"; fmt.Sprint(g.Order) != want {
+		t.Errorf("g.Order: want %s, got %v", want, g.Order)
+	}
+	if want := "[https://example.com/b]"; fmt.Sprint(queue) != want {
+		t.Errorf("queue: want %s, got %v", want, queue)
+	}
+	if p := g.Pages["https://example.com/b"]; p.InboundLinks != 1 || p.Depth != 1 {
+		t.Errorf("Pages[/b]: want depth 1 and 1 inbound link, got %+v", p)
+	}
+
+	if err := state.Finish(); err != nil {
+		t.Fatalf("Finish() received an error: %v", err)
+	}
+	if _, _, _, err := state.Start("https://example.com", 1, true); err == nil {
+		t.Errorf("Start(): expected an error resuming a finished crawl")
+	}
+}
+
+func TestCrawl_incremental(t *testing.T) {
+	var fullResponses int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullResponses++
+		fmt.Fprint(w, `<a href="/a">A</a>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	state, teardown := openTestCrawlState(t)
+	defer teardown()
+
+	for i := 0; i < 2; i++ {
+		scope := newTestScope(t, server.URL)
+		c := newCrawler(crawlerOptions{Scope: scope, Concurrency: 1, Timeout: time.Second})
+		g, err := crawl(context.Background(), c, scope.Base(), 1, state, false)
+		if err != nil {
+			t.Fatalf("crawl() received an error: %v", err)
+		}
+		if len(g.SitemapPages()) != 2 {
+			t.Errorf("crawl %d: want 2 sitemap pages, got %d", i, len(g.SitemapPages()))
+		}
+		if notModified := g.Pages[scope.Base()].NotModified; notModified != (i == 1) {
+			t.Errorf("crawl %d: want NotModified %v, got %v", i, i == 1, notModified)
+		}
+	}
+	if fullResponses != 2 {
+		t.Errorf("fullResponses: want %d (both pages once), got %d", 2, fullResponses)
+	}
+}
+
+func openTestCrawlState(t *testing.T) (*crawlState, func()) {
+	dir, err := ioutil.TempDir("", "sitemap")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	state, err := openCrawlState(filepath.Join(dir, "crawl.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed to open crawl state: %v", err)
+	}
+	return state, func() {
+		state.Close()
+		os.RemoveAll(dir)
+	}
+}
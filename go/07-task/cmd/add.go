@@ -3,11 +3,25 @@ package cmd
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
+var addFlags struct {
+	due      string
+	priority string
+	tags     []string
+	project  string
+	repeat   string
+}
+
 func init() {
+	addCmd.Flags().StringVar(&addFlags.due, "due", "", `due date, as in "tomorrow", "friday", "in 3 days" or "2020-05-01"`)
+	addCmd.Flags().StringVar(&addFlags.priority, "priority", "", "priority, one of none, low, medium or high")
+	addCmd.Flags().StringSliceVar(&addFlags.tags, "tag", nil, "tag to add, can be repeated")
+	addCmd.Flags().StringVar(&addFlags.project, "project", "", "project the task belongs to")
+	addCmd.Flags().StringVar(&addFlags.repeat, "repeat", "", `repeat the task, as in "daily", "weekly on mon,thu", "monthly" or "every 3 days"`)
 	rootCmd.AddCommand(addCmd)
 }
 
@@ -19,7 +33,38 @@ var addCmd = &cobra.Command{
 			exitf("Missing task details\n")
 		}
 
-		task := Task{Details: strings.Join(args, " ")}
+		task := Task{
+			Details: strings.Join(args, " "),
+			Tags:    normalizeTags(addFlags.tags),
+			Project: strings.TrimSpace(addFlags.project),
+		}
+		now := time.Now()
+		if addFlags.due != "" {
+			due, err := parseDate(addFlags.due, now)
+			if err != nil {
+				exitf("%v\n", err)
+			}
+			task.Due = &due
+		}
+		if addFlags.repeat != "" {
+			r, err := parseRecurrence(addFlags.repeat)
+			if err != nil {
+				exitf("%v\n", err)
+			}
+			if task.Due == nil {
+				due := r.First(now)
+				task.Due = &due
+			}
+			if r.Freq == freqMonthly {
+				r.MonthDay = task.Due.Day()
+			}
+			task.Recurrence = r
+		}
+		priority, err := ParsePriority(addFlags.priority)
+		if err != nil {
+			exitf("%v\n", err)
+		}
+		task.Priority = priority
 
 		if err := CreateTask(&task); err != nil {
 			exitf("%v\n", err)
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The list of formats tasks can be exported to and imported from
+const (
+	formatJSON    = "json"
+	formatCSV     = "csv"
+	formatTodoTxt = "todotxt"
+)
+
+var csvHeader = []string{"id", "details", "completed", "priority", "due", "project", "tags", "recurrence", "created_at", "completed_at"}
+
+// formatOf returns the format of path by its extension, defaulting to JSON
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return formatCSV
+	case ".txt":
+		return formatTodoTxt
+	default:
+		return formatJSON
+	}
+}
+
+func writeTasks(w io.Writer, format string, tasks []*Task) error {
+	switch format {
+	case formatJSON:
+		if tasks == nil {
+			tasks = []*Task{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tasks)
+
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			var recurrence string
+			if t.Recurrence != nil {
+				recurrence = t.Recurrence.String()
+			}
+			if err := cw.Write([]string{
+				strconv.Itoa(t.ID),
+				t.Details,
+				strconv.FormatBool(t.Completed),
+				t.Priority.String(),
+				formatTime(t.Due, dateLayout),
+				t.Project,
+				strings.Join(t.Tags, " "),
+				recurrence,
+				formatTime(t.CreatedAt, time.RFC3339),
+				formatTime(t.CompletedAt, time.RFC3339),
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case formatTodoTxt:
+		bw := bufio.NewWriter(w)
+		for _, t := range tasks {
+			if _, err := fmt.Fprintln(bw, formatTodoTxtTask(t)); err != nil {
+				return err
+			}
+		}
+		return bw.Flush()
+
+	default:
+		return fmt.Errorf("unknown format %q, expected %s, %s or %s", format, formatJSON, formatCSV, formatTodoTxt)
+	}
+}
+
+func readTasks(r io.Reader, format string) ([]*Task, error) {
+	switch format {
+	case formatJSON:
+		var tasks []*Task
+		if err := json.NewDecoder(r).Decode(&tasks); err != nil {
+			return nil, err
+		}
+		return tasks, nil
+
+	case formatCSV:
+		records, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, nil
+		}
+		columns := map[string]int{}
+		for i, name := range records[0] {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		if _, ok := columns["details"]; !ok {
+			return nil, fmt.Errorf("missing the details column")
+		}
+
+		var tasks []*Task
+		for i, record := range records[1:] {
+			t, err := parseCSVTask(record, columns)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+2, err)
+			}
+			tasks = append(tasks, t)
+		}
+		return tasks, nil
+
+	case formatTodoTxt:
+		var tasks []*Task
+		s := bufio.NewScanner(r)
+		for line := 1; s.Scan(); line++ {
+			if strings.TrimSpace(s.Text()) == "" {
+				continue
+			}
+			t, err := parseTodoTxtTask(s.Text())
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			tasks = append(tasks, t)
+		}
+		return tasks, s.Err()
+
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected %s, %s or %s", format, formatJSON, formatCSV, formatTodoTxt)
+	}
+}
+
+// parseCSVTask parses a record with the columns of csvHeader, in any order,
+// where only details is required
+func parseCSVTask(record []string, columns map[string]int) (*Task, error) {
+	get := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	t := &Task{
+		Details: get("details"),
+		Project: get("project"),
+		Tags:    normalizeTags(strings.Fields(get("tags"))),
+	}
+	var err error
+	if s := get("id"); s != "" {
+		if t.ID, err = strconv.Atoi(s); err != nil {
+			return nil, fmt.Errorf("invalid id %q", s)
+		}
+	}
+	if s := get("completed"); s != "" {
+		if t.Completed, err = strconv.ParseBool(s); err != nil {
+			return nil, fmt.Errorf("invalid completed %q", s)
+		}
+	}
+	if t.Priority, err = ParsePriority(get("priority")); err != nil {
+		return nil, err
+	}
+	if t.Due, err = parseTime(get("due"), dateLayout); err != nil {
+		return nil, err
+	}
+	if t.CreatedAt, err = parseTime(get("created_at"), time.RFC3339); err != nil {
+		return nil, err
+	}
+	if t.CompletedAt, err = parseTime(get("completed_at"), time.RFC3339); err != nil {
+		return nil, err
+	}
+	if s := get("recurrence"); s != "" {
+		if t.Recurrence, err = parseRecurrence(s); err != nil {
+			return nil, err
+		}
+		if t.Recurrence.Freq == freqMonthly && t.Due != nil {
+			t.Recurrence.MonthDay = t.Due.Day()
+		}
+	}
+	return t, nil
+}
+
+func formatTime(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// parseTime parses s with layout in the local time zone, returning nil if
+// it's empty
+func parseTime(s, layout string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(layout, s, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWriteReadTasks(t *testing.T) {
+	day := func(d int) *time.Time {
+		t := time.Date(2020, time.May, d, 0, 0, 0, 0, time.Local)
+		return &t
+	}
+	weekly, _ := parseRecurrence("every 2 weeks")
+	tasks := []*Task{
+		{ID: 1, Details: "write report", Due: day(8), Priority: PriorityHigh, Tags: []string{"work"}, Project: "q3", CreatedAt: day(1)},
+		{ID: 3, Details: "water plants", Due: day(9), Tags: []string{"home", "weekend"}, Recurrence: weekly, CreatedAt: day(2)},
+		{ID: 4, Details: "buy milk", Completed: true, Priority: PriorityLow, CreatedAt: day(3), CompletedAt: day(4)},
+	}
+
+	for _, format := range []string{formatJSON, formatCSV, formatTodoTxt} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeTasks(&buf, format, tasks); err != nil {
+				t.Fatalf("writeTasks() received an error: %v", err)
+			}
+			got, err := readTasks(&buf, format)
+			if err != nil {
+				t.Fatalf("readTasks() received an error: %v", err)
+			}
+
+			want, _ := json.Marshal(tasks)
+			if b, _ := json.Marshal(got); string(b) != string(want) {
+				t.Errorf("want %s, got %s", want, b)
+			}
+		})
+	}
+
+	if err := writeTasks(&bytes.Buffer{}, "xml", tasks); err == nil {
+		t.Errorf("writeTasks(): expected an error for an unknown format")
+	}
+}
+
+func TestTodoTxt(t *testing.T) {
+	cases := []struct {
+		line string
+		want Task
+	}{
+		{"(A) call mom", Task{Details: "call mom", Priority: PriorityHigh}},
+		{"(D) 2020-05-01 call +Family mom @phone @Home due:2020-05-03",
+			Task{Details: "call mom", Priority: PriorityLow, Project: "Family", Tags: []string{"home", "phone"}}},
+		{"x 2020-05-02 2020-05-01 pay rent pri:B rec:+1m id:7 url:http://x",
+			Task{ID: 7, Details: "pay rent url:http://x", Completed: true, Priority: PriorityMedium}},
+		{"x done +a +b", Task{Details: "done", Completed: true, Project: "a", Tags: []string{"b"}}},
+	}
+	for _, c := range cases {
+		got, err := parseTodoTxtTask(c.line)
+		if err != nil {
+			t.Fatalf("parseTodoTxtTask(%q) received an error: %v", c.line, err)
+		}
+		if got.ID != c.want.ID || got.Details != c.want.Details || got.Completed != c.want.Completed ||
+			got.Priority != c.want.Priority || got.Project != c.want.Project || !sameStrings(got.Tags, c.want.Tags) {
+			t.Errorf("parseTodoTxtTask(%q): want %+v, got %+v", c.line, c.want, *got)
+		}
+	}
+
+	got, err := parseTodoTxtTask("x 2020-05-02 2020-05-01 pay rent rec:1m due:2020-01-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CompletedAt.Format(dateLayout) != "2020-05-02" || got.CreatedAt.Format(dateLayout) != "2020-05-01" ||
+		got.Recurrence.String() != "monthly" || got.Recurrence.MonthDay != 31 {
+		t.Errorf("parseTodoTxtTask(): want the dates and recurrence, got %+v", got)
+	}
+
+	for _, line := range []string{"", "(A) +project", "write due:tomorrow", "write rec:2b"} {
+		if _, err := parseTodoTxtTask(line); err == nil {
+			t.Errorf("parseTodoTxtTask(%q): expected an error", line)
+		}
+	}
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -3,31 +3,88 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
+var completedFlags struct {
+	since string
+	today bool
+	week  bool
+	all   bool
+}
+
 func init() {
+	completedCmd.Flags().StringVar(&completedFlags.since, "since", "", `only list tasks completed since this long ago or this date, as in "24h", "7d" or "monday"`)
+	completedCmd.Flags().BoolVar(&completedFlags.today, "today", false, "only list tasks completed today (the default)")
+	completedCmd.Flags().BoolVar(&completedFlags.week, "week", false, "only list tasks completed this week")
+	completedCmd.Flags().BoolVar(&completedFlags.all, "all", false, "list every completed task")
 	rootCmd.AddCommand(completedCmd)
 }
 
 var completedCmd = &cobra.Command{
 	Use:   "completed",
-	Short: "List all of your completed tasks",
+	Short: "List your completed tasks",
 	Run: func(cmd *cobra.Command, _ []string) {
+		now := time.Now()
+
+		var periods int
+		for _, set := range []bool{completedFlags.since != "", completedFlags.today, completedFlags.week, completedFlags.all} {
+			if set {
+				periods++
+			}
+		}
+		if periods > 1 {
+			exitf("Only one of --since, --today, --week and --all can be used\n")
+		}
+
+		filter := taskFilter{Now: now}
+		period := "today"
+		switch {
+		case completedFlags.all:
+			period = ""
+		case completedFlags.week:
+			since := startOfWeek(now)
+			filter.CompletedSince = &since
+			period = "this week"
+		case completedFlags.since != "":
+			since, err := parseSince(completedFlags.since, now)
+			if err != nil {
+				exitf("%v\n", err)
+			}
+			filter.CompletedSince = &since
+			period = "since " + since.Format("2006-01-02 15:04")
+		default:
+			since := startOfDay(now)
+			filter.CompletedSince = &since
+		}
+
 		tasks, err := ListTasks(true)
 		if err != nil {
 			exitf("%v\n", err)
 		}
+		tasks = filterTasks(tasks, &filter)
+		if err := sortTasks(tasks, "completed"); err != nil {
+			exitf("%v\n", err)
+		}
 
 		if len(tasks) == 0 {
-			fmt.Println("You don't have any completed tasks.")
+			if period == "" {
+				fmt.Println("You don't have any completed tasks.")
+			} else {
+				fmt.Printf("You haven't completed any tasks %s.\n", period)
+			}
 			os.Exit(0)
 		}
 
-		fmt.Println("You have finished the following tasks today:")
+		if period == "" {
+			fmt.Println("You have finished the following tasks:")
+		} else {
+			fmt.Printf("You have finished the following tasks %s:\n", period)
+		}
 		for _, t := range tasks {
-			fmt.Printf("%d. %s\n", t.ID, t.Details)
+			printTask(t, now)
 		}
 	},
 }
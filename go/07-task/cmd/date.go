@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	relativeDateRegexp = regexp.MustCompile(`^(?:in\s+)?(\d+)\s*(d|days?|w|weeks?|m|months?|y|years?)$`)
+	durationDaysRegexp = regexp.MustCompile(`^(\d+)(d|w)$`)
+	weekdays           = map[string]time.Weekday{
+		"sun": time.Sunday, "sunday": time.Sunday,
+		"mon": time.Monday, "monday": time.Monday,
+		"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+		"wed": time.Wednesday, "wednesday": time.Wednesday,
+		"thu": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+		"fri": time.Friday, "friday": time.Friday,
+		"sat": time.Saturday, "saturday": time.Saturday,
+	}
+)
+
+// parseDate parses a date relative to now, in any of the forms:
+//
+//	today, tomorrow, yesterday
+//	monday, next friday (the next one after today)
+//	next week, next month, next year
+//	in 3 days, 2 weeks, 1m, 10d
+//	2020-05-01, May 1, 1 May (in the current year if it's missing)
+//
+// and returns the start of that day in now's location.
+func parseDate(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	today := startOfDay(now)
+
+	switch s {
+	case "today", "now":
+		return today, nil
+	case "tomorrow", "tmrw":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "next week":
+		return today.AddDate(0, 0, 7), nil
+	case "next month":
+		return today.AddDate(0, 1, 0), nil
+	case "next year":
+		return today.AddDate(1, 0, 0), nil
+	}
+
+	if wd, ok := weekdays[strings.TrimPrefix(s, "next ")]; ok {
+		days := (int(wd) - int(today.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, days), nil
+	}
+
+	if m := relativeDateRegexp.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, err
+		}
+		switch m[2][0] {
+		case 'd':
+			return today.AddDate(0, 0, n), nil
+		case 'w':
+			return today.AddDate(0, 0, 7*n), nil
+		case 'm':
+			return today.AddDate(0, n, 0), nil
+		case 'y':
+			return today.AddDate(n, 0, 0), nil
+		}
+	}
+
+	for _, layout := range []string{dateLayout, "2006/01/02"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"Jan 2", "January 2", "2 Jan", "2 January", "Jan 2 2006", "January 2 2006", "2 Jan 2006", "2 January 2006"} {
+		t, err := time.ParseInLocation(layout, strings.Replace(s, ",", "", -1), now.Location())
+		if err != nil {
+			continue
+		}
+		if t.Year() == 0 {
+			t = t.AddDate(now.Year(), 0, 0)
+		}
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// parseSince parses the start of a period ending now, either as a duration,
+// as in "24h", "90m", "7d" or "2w", as the last given weekday, or as a date
+// parseDate understands
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if m := durationDaysRegexp.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, err
+		}
+		if m[2] == "w" {
+			n *= 7
+		}
+		return now.Add(-time.Duration(n) * 24 * time.Hour), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	// since monday means since the last one, not the next one
+	if wd, ok := weekdays[strings.ToLower(s)]; ok {
+		days := (int(now.Weekday()) - int(wd) + 7) % 7
+		return startOfDay(now).AddDate(0, 0, -days), nil
+	}
+	return parseDate(s, now)
+}
+
+// startOfWeek returns the start of the Monday of t's week
+func startOfWeek(t time.Time) time.Time {
+	days := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -days)
+}
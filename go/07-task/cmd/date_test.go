@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2020, time.May, 6, 15, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"today", "2020-05-06"},
+		{"Tomorrow", "2020-05-07"},
+		{"yesterday", "2020-05-05"},
+		{"friday", "2020-05-08"},
+		{"next fri", "2020-05-08"},
+		{"wednesday", "2020-05-13"},
+		{"monday", "2020-05-11"},
+		{"next week", "2020-05-13"},
+		{"next month", "2020-06-06"},
+		{"in 3 days", "2020-05-09"},
+		{"2 weeks", "2020-05-20"},
+		{"10d", "2020-05-16"},
+		{"1m", "2020-06-06"},
+		{"2020-12-31", "2020-12-31"},
+		{"2020/01/02", "2020-01-02"},
+		{"May 20", "2020-05-20"},
+		{"20 may", "2020-05-20"},
+		{"Jan 2, 2021", "2021-01-02"},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			got, err := parseDate(c.in, now)
+			if err != nil {
+				t.Fatalf("parseDate(%q) received an error: %v", c.in, err)
+			}
+			if got.Format(dateLayout) != c.want {
+				t.Errorf("parseDate(%q): want %s, got %s", c.in, c.want, got.Format(dateLayout))
+			}
+			if got != startOfDay(got) {
+				t.Errorf("parseDate(%q): want the start of the day, got %v", c.in, got)
+			}
+		})
+	}
+
+	for _, in := range []string{"", "someday", "in a bit", "2020-13-01"} {
+		if _, err := parseDate(in, now); err == nil {
+			t.Errorf("parseDate(%q): expected an error", in)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2020, time.May, 6, 15, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"24h", "2020-05-05 15:30"},
+		{"90m", "2020-05-06 14:00"},
+		{"7d", "2020-04-29 15:30"},
+		{"2w", "2020-04-22 15:30"},
+		{"monday", "2020-05-04 00:00"},
+		{"Wednesday", "2020-05-06 00:00"},
+		{"yesterday", "2020-05-05 00:00"},
+	}
+	for _, c := range cases {
+		got, err := parseSince(c.in, now)
+		if err != nil {
+			t.Fatalf("parseSince(%q) received an error: %v", c.in, err)
+		}
+		if got.Format("2006-01-02 15:04") != c.want {
+			t.Errorf("parseSince(%q): want %s, got %s", c.in, c.want, got.Format("2006-01-02 15:04"))
+		}
+	}
+
+	if got := startOfWeek(now).Format(dateLayout); got != "2020-05-04" {
+		t.Errorf("startOfWeek(): want 2020-05-04, got %s", got)
+	}
+}
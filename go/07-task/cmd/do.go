@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"strconv"
 
 	"github.com/spf13/cobra"
 )
@@ -12,20 +11,32 @@ func init() {
 }
 
 var doCmd = &cobra.Command{
-	Use:   "do",
-	Short: "Mark a task on your TODO list as complete",
+	Use:   "do <id>...",
+	Short: "Mark tasks on your TODO list as complete",
+	Long:  "Mark tasks on your TODO list as complete, given as IDs or ranges of them, as in \"task do 1 3 5-7\".",
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			exitf("Missing task ID\n")
 		}
-		id, err := strconv.Atoi(args[0])
+		ids, err := parseIDs(args)
 		if err != nil {
 			exitf("%v\n", err)
 		}
-		task := Task{ID: id}
-		if err := MarkTaskAsCompleted(&task); err != nil {
+		tasks := tasksWithIDs(ids)
+		completions, err := MarkTasksAsCompleted(tasks...)
+		if err != nil {
 			exitf("%v\n", err)
 		}
-		fmt.Printf("You have completed the %q task.\n", task.Details)
+		for i, task := range tasks {
+			c := completions[i]
+			if c.AlreadyCompleted {
+				fmt.Printf("You have already completed the %q task.\n", task.Details)
+				continue
+			}
+			fmt.Printf("You have completed the %q task.\n", task.Details)
+			if c.Next != nil {
+				fmt.Printf("It repeats %s, and is due again on %s as task %d.\n", c.Next.Recurrence, c.Next.Due.Format(dateLayout), c.Next.ID)
+			}
+		}
 	},
 }
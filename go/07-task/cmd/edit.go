@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit <id> <details>",
+	Short: "Change the details of a task",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			exitf("Missing task ID\n")
+		}
+		id, err := parseID(args[0])
+		if err != nil {
+			exitf("%v\n", err)
+		}
+		if len(args) == 1 {
+			exitf("Missing task details\n")
+		}
+
+		task, err := GetTask(id)
+		if err != nil {
+			exitf("%v\n", err)
+		}
+		old := task.Details
+		task.Details = strings.Join(args[1:], " ")
+		if err := UpdateTask(task); err != nil {
+			exitf("%v\n", err)
+		}
+		fmt.Printf("You have changed %q to %q.\n", old, task.Details)
+	},
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var exportFlags struct {
+	format   string
+	output   string
+	archived bool
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFlags.format, "format", "", "format to export to, json, csv or todotxt, defaults to the one of --output, or json")
+	exportCmd.Flags().StringVarP(&exportFlags.output, "output", "o", "", "file to export to, defaults to stdout")
+	exportCmd.Flags().BoolVar(&exportFlags.archived, "archived", false, "export the deleted tasks too")
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export your tasks to JSON, CSV or todo.txt",
+	Long: `Export your tasks to JSON, CSV or todo.txt.
+
+todo.txt keeps task IDs, due dates and recurrences in id:, due: and rec: tags.
+Weekly recurrences on given days, other than every weekday, are left out of it.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		format := exportFlags.format
+		if format == "" {
+			format = formatOf(exportFlags.output)
+		}
+
+		var tasks []*Task
+		for _, completed := range []bool{false, true} {
+			ts, err := ListTasks(completed)
+			if err != nil {
+				exitf("%v\n", err)
+			}
+			tasks = append(tasks, ts...)
+		}
+		if exportFlags.archived {
+			archived, err := ListArchivedTasks()
+			if err != nil {
+				exitf("%v\n", err)
+			}
+			tasks = append(tasks, archived...)
+		}
+		if err := sortTasks(tasks, "id"); err != nil {
+			exitf("%v\n", err)
+		}
+
+		var w io.Writer = os.Stdout
+		if exportFlags.output != "" {
+			f, err := os.Create(exportFlags.output)
+			if err != nil {
+				exitf("%v\n", err)
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := writeTasks(w, format, tasks); err != nil {
+			exitf("%v\n", err)
+		}
+	},
+}
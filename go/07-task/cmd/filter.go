@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// taskFilter keeps the tasks matching all of its non-zero fields
+type taskFilter struct {
+	// Tags are all required on a task
+	Tags    []string
+	Project string
+	// Priority is the lowest priority a task may have
+	Priority Priority
+	// DueBefore keeps the tasks due on or before it
+	DueBefore *time.Time
+	Overdue   bool
+	// CompletedSince keeps the tasks completed at or after it
+	CompletedSince *time.Time
+	Now            time.Time
+}
+
+func (f *taskFilter) Match(t *Task) bool {
+	for _, tag := range f.Tags {
+		if !t.HasTag(tag) {
+			return false
+		}
+	}
+	if f.Project != "" && !strings.EqualFold(t.Project, f.Project) {
+		return false
+	}
+	if t.Priority < f.Priority {
+		return false
+	}
+	if f.DueBefore != nil && (t.Due == nil || t.Due.After(*f.DueBefore)) {
+		return false
+	}
+	if f.Overdue && !t.Overdue(f.Now) {
+		return false
+	}
+	if f.CompletedSince != nil && (t.CompletedAt == nil || t.CompletedAt.Before(*f.CompletedSince)) {
+		return false
+	}
+	return true
+}
+
+func filterTasks(tasks []*Task, f *taskFilter) []*Task {
+	var filtered []*Task
+	for _, t := range tasks {
+		if f.Match(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// The list of keys tasks can be sorted by
+var taskSortKeys = map[string]func(a, b *Task) bool{
+	"id": func(a, b *Task) bool {
+		return a.ID < b.ID
+	},
+	// tasks without a due date go last
+	"due": func(a, b *Task) bool {
+		switch {
+		case a.Due == nil || b.Due == nil:
+			return a.Due != nil && b.Due == nil
+		default:
+			return a.Due.Before(*b.Due)
+		}
+	},
+	// highest priority first
+	"priority": func(a, b *Task) bool {
+		return a.Priority > b.Priority
+	},
+	// tasks with an unknown completion time go first
+	"completed": func(a, b *Task) bool {
+		if a.CompletedAt == nil || b.CompletedAt == nil {
+			return a.CompletedAt == nil && b.CompletedAt != nil
+		}
+		return a.CompletedAt.Before(*b.CompletedAt)
+	},
+	// tasks without a project go last
+	"project": func(a, b *Task) bool {
+		if a.Project == "" || b.Project == "" {
+			return a.Project != "" && b.Project == ""
+		}
+		return strings.ToLower(a.Project) < strings.ToLower(b.Project)
+	},
+}
+
+// sortTasks sorts tasks by key, breaking ties by ID
+func sortTasks(tasks []*Task, key string) error {
+	less, ok := taskSortKeys[key]
+	if !ok {
+		var keys []string
+		for k := range taskSortKeys {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return fmt.Errorf("unknown sort key %q, expected one of %s", key, strings.Join(keys, ", "))
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if less(tasks[i], tasks[j]) {
+			return true
+		}
+		if less(tasks[j], tasks[i]) {
+			return false
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFilterAndSortTasks(t *testing.T) {
+	now := time.Date(2020, time.May, 6, 12, 0, 0, 0, time.UTC)
+	day := func(d int) *time.Time {
+		t := time.Date(2020, time.May, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	tasks := []*Task{
+		{ID: 1, Details: "a", Due: day(5), CompletedAt: day(6), Priority: PriorityLow, Tags: []string{"work"}, Project: "q3"},
+		{ID: 2, Details: "b", Priority: PriorityHigh, Tags: []string{"home"}},
+		{ID: 3, Details: "c", Due: day(10), CompletedAt: day(4), Priority: PriorityMedium, Tags: []string{"home", "work"}, Project: "Q3"},
+		{ID: 4, Details: "d", Due: day(7)},
+	}
+
+	cases := []struct {
+		name   string
+		filter taskFilter
+		sort   string
+		want   []int
+	}{
+		{"all", taskFilter{}, "id", []int{1, 2, 3, 4}},
+		{"tag", taskFilter{Tags: []string{"Work"}}, "id", []int{1, 3}},
+		{"tags", taskFilter{Tags: []string{"work", "home"}}, "id", []int{3}},
+		{"project", taskFilter{Project: "q3"}, "id", []int{1, 3}},
+		{"priority", taskFilter{Priority: PriorityMedium}, "id", []int{2, 3}},
+		{"due before", taskFilter{DueBefore: day(7)}, "id", []int{1, 4}},
+		{"overdue", taskFilter{Overdue: true, Now: now}, "id", []int{1}},
+		{"completed since", taskFilter{CompletedSince: day(5)}, "id", []int{1}},
+		{"by due", taskFilter{}, "due", []int{1, 4, 3, 2}},
+		{"by priority", taskFilter{}, "priority", []int{2, 3, 1, 4}},
+		{"by project", taskFilter{}, "project", []int{1, 3, 2, 4}},
+		{"by completion", taskFilter{}, "completed", []int{2, 4, 3, 1}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := filterTasks(append([]*Task(nil), tasks...), &c.filter)
+			if err := sortTasks(got, c.sort); err != nil {
+				t.Fatalf("sortTasks() received an error: %v", err)
+			}
+			var ids []int
+			for _, task := range got {
+				ids = append(ids, task.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(c.want) {
+				t.Errorf("want %v, got %v", c.want, ids)
+			}
+		})
+	}
+
+	if err := sortTasks(tasks, "color"); err == nil {
+		t.Errorf("sortTasks(): expected an error for an unknown key")
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxIDRange caps the size of ranges like 1-100, so a typo can't expand into
+// millions of IDs
+const maxIDRange = 1000
+
+// parseIDs parses task IDs given as separate arguments, comma-separated lists
+// or ranges, as in "1 3 5-7" or "1,3,5-7". Duplicates are dropped.
+func parseIDs(args []string) ([]int, error) {
+	var ids []int
+	seen := map[int]bool{}
+	add := func(id int) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, arg := range args {
+		for _, s := range strings.Split(arg, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+
+			if i := strings.Index(s, "-"); i > 0 {
+				from, err := parseID(s[:i])
+				if err != nil {
+					return nil, err
+				}
+				to, err := parseID(s[i+1:])
+				if err != nil {
+					return nil, err
+				}
+				if from > to {
+					return nil, fmt.Errorf("invalid range %q", s)
+				}
+				if to-from >= maxIDRange {
+					return nil, fmt.Errorf("range %q is too large", s)
+				}
+				for id := from; id <= to; id++ {
+					add(id)
+				}
+				continue
+			}
+
+			id, err := parseID(s)
+			if err != nil {
+				return nil, err
+			}
+			add(id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no task IDs in %q", strings.Join(args, " "))
+	}
+	return ids, nil
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid task ID %q", s)
+	}
+	return id, nil
+}
+
+// tasksWithIDs returns tasks with only their IDs set, to be loaded by a Store
+func tasksWithIDs(ids []int) []*Task {
+	tasks := make([]*Task, len(ids))
+	for i, id := range ids {
+		tasks[i] = &Task{ID: id}
+	}
+	return tasks
+}
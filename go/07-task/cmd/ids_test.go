@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseIDs(t *testing.T) {
+	cases := []struct {
+		args []string
+		want []int
+	}{
+		{[]string{"1"}, []int{1}},
+		{[]string{"1", "3", "5-7"}, []int{1, 3, 5, 6, 7}},
+		{[]string{"1,3,5-7"}, []int{1, 3, 5, 6, 7}},
+		{[]string{"3", "1-4", "2"}, []int{3, 1, 2, 4}},
+		{[]string{"2-2"}, []int{2}},
+	}
+	for _, c := range cases {
+		got, err := parseIDs(c.args)
+		if err != nil {
+			t.Fatalf("parseIDs(%q) received an error: %v", c.args, err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("parseIDs(%q): want %v, got %v", c.args, c.want, got)
+		}
+	}
+
+	for _, args := range [][]string{{}, {","}, {"a"}, {"0"}, {"-1"}, {"5-3"}, {"1-"}, {"1-100000"}} {
+		if _, err := parseIDs(args); err == nil {
+			t.Errorf("parseIDs(%q): expected an error", args)
+		}
+	}
+}
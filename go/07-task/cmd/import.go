@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var importFlags struct {
+	format string
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importFlags.format, "format", "", "format to import from, json, csv or todotxt, defaults to the one of the file, or json")
+	rootCmd.AddCommand(importCmd)
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import tasks from JSON, CSV or todo.txt",
+	Long: `Import tasks from JSON, CSV or todo.txt, read from file or stdin.
+
+Tasks keep their IDs, unless they're taken, in which case they get new ones.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := importFlags.format
+		var r io.Reader = os.Stdin
+		if len(args) == 1 && args[0] != "-" {
+			if format == "" {
+				format = formatOf(args[0])
+			}
+			f, err := os.Open(args[0])
+			if err != nil {
+				exitf("%v\n", err)
+			}
+			defer f.Close()
+			r = f
+		}
+		if format == "" {
+			format = formatJSON
+		}
+
+		tasks, err := readTasks(r, format)
+		if err != nil {
+			exitf("%v\n", err)
+		}
+		if len(tasks) == 0 {
+			fmt.Println("There are no tasks to import.")
+			os.Exit(0)
+		}
+
+		ids := make([]int, len(tasks))
+		for i, t := range tasks {
+			ids[i] = t.ID
+		}
+		if err := ImportTasks(tasks...); err != nil {
+			exitf("%v\n", err)
+		}
+
+		fmt.Printf("Imported %s.\n", pluralize(len(tasks), "task"))
+		for i, t := range tasks {
+			if ids[i] > 0 && t.ID != ids[i] {
+				fmt.Printf("Task %d was imported as %d, since %d is taken.\n", ids[i], t.ID, ids[i])
+			}
+		}
+	},
+}
@@ -3,11 +3,27 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
+var listFlags struct {
+	tags      []string
+	project   string
+	priority  string
+	dueBefore string
+	overdue   bool
+	sort      string
+}
+
 func init() {
+	listCmd.Flags().StringSliceVar(&listFlags.tags, "tag", nil, "only list tasks with this tag, can be repeated")
+	listCmd.Flags().StringVar(&listFlags.project, "project", "", "only list tasks in this project")
+	listCmd.Flags().StringVar(&listFlags.priority, "priority", "", "only list tasks with at least this priority")
+	listCmd.Flags().StringVar(&listFlags.dueBefore, "due", "", `only list tasks due by this date, as in "friday"`)
+	listCmd.Flags().BoolVar(&listFlags.overdue, "overdue", false, "only list overdue tasks")
+	listCmd.Flags().StringVar(&listFlags.sort, "sort", "id", "sort tasks by id, due, priority or project")
 	rootCmd.AddCommand(listCmd)
 }
 
@@ -15,10 +31,34 @@ var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all of your incomplete tasks",
 	Run: func(cmd *cobra.Command, _ []string) {
+		now := time.Now()
+		filter := taskFilter{
+			Tags:    listFlags.tags,
+			Project: listFlags.project,
+			Overdue: listFlags.overdue,
+			Now:     now,
+		}
+		priority, err := ParsePriority(listFlags.priority)
+		if err != nil {
+			exitf("%v\n", err)
+		}
+		filter.Priority = priority
+		if listFlags.dueBefore != "" {
+			due, err := parseDate(listFlags.dueBefore, now)
+			if err != nil {
+				exitf("%v\n", err)
+			}
+			filter.DueBefore = &due
+		}
+
 		tasks, err := ListTasks(false)
 		if err != nil {
 			exitf("%v\n", err)
 		}
+		tasks = filterTasks(tasks, &filter)
+		if err := sortTasks(tasks, listFlags.sort); err != nil {
+			exitf("%v\n", err)
+		}
 
 		if len(tasks) == 0 {
 			fmt.Println("You don't have any incomplete tasks.")
@@ -27,7 +67,18 @@ var listCmd = &cobra.Command{
 
 		fmt.Println("You have the following tasks:")
 		for _, t := range tasks {
-			fmt.Printf("%d. %s\n", t.ID, t.Details)
+			printTask(t, now)
 		}
 	},
 }
+
+func printTask(t *Task, now time.Time) {
+	line := fmt.Sprintf("%d. %s", t.ID, t.Details)
+	if meta := t.Meta(); meta != "" {
+		line += " (" + meta + ")"
+	}
+	if t.Overdue(now) {
+		line += " [overdue]"
+	}
+	fmt.Println(line)
+}
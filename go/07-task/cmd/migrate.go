@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// migrations bring the tasks bucket from one schema version to the next, the
+// first one starting from the unversioned records of the original Task with
+// only an ID, details and whether it's completed. New migrations are only
+// ever appended.
+var migrations = []func(tx *bolt.Tx) error{
+	// 1: due dates, priorities, tags and projects
+	func(tx *bolt.Tx) error {
+		return updateTasks(tx, func(task *Task) {
+			task.Tags = normalizeTags(task.Tags)
+		})
+	},
+	// 2: UIDs and update times to sync by
+	func(tx *bolt.Tx) error {
+		return updateTasks(tx, assignUID)
+	},
+}
+
+// assignUID gives task a UID and an update time if it has none, for a
+// migration
+func assignUID(task *Task) {
+	if task.UID != "" {
+		return
+	}
+	updated := time.Now()
+	if task.CreatedAt != nil {
+		updated = *task.CreatedAt
+	}
+	task.touch(updated)
+}
+
+// migrate runs the migrations the database hasn't seen yet, recording the
+// new schema version along with them in tx
+func migrate(tx *bolt.Tx) error {
+	meta := tx.Bucket(metaBucketName)
+
+	version := 0
+	if b := meta.Get(schemaVersionKey); b != nil {
+		v, err := strconv.Atoi(string(b))
+		if err != nil {
+			return fmt.Errorf("invalid schema version %q: %v", b, err)
+		}
+		version = v
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("the database has schema version %d, newer than the supported %d", version, len(migrations))
+	}
+	if version == len(migrations) {
+		return nil
+	}
+
+	for i, m := range migrations[version:] {
+		if err := m(tx); err != nil {
+			return fmt.Errorf("migrating to schema version %d: %v", version+i+1, err)
+		}
+	}
+	return meta.Put(schemaVersionKey, []byte(strconv.Itoa(len(migrations))))
+}
+
+// updateTasks rewrites every task in the tasks bucket after passing it to fn
+func updateTasks(tx *bolt.Tx, fn func(*Task)) error {
+	bucket := tx.Bucket(tasksBucketName)
+
+	updated := map[string][]byte{}
+	if err := bucket.ForEach(func(k, b []byte) error {
+		var task Task
+		if err := json.Unmarshal(b, &task); err != nil {
+			return fmt.Errorf("task %d: %v", btoi(k), err)
+		}
+		fn(&task)
+		b, err := json.Marshal(&task)
+		if err != nil {
+			return err
+		}
+		updated[string(k)] = b
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// a bucket can't be modified while iterating over it
+	for k, b := range updated {
+		if err := bucket.Put([]byte(k), b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestMigrate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := bolt.Open(filepath.Join(dir, "tasks.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// records written before tasks had a schema version
+	if err := db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket(tasksBucketName)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(itob(1), []byte(`{"id":1,"details":"write report","completed":false}`)); err != nil {
+			return err
+		}
+		return bucket.Put(itob(2), []byte(`{"id":2,"details":"buy milk","completed":true}`))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := db.Update(func(tx *bolt.Tx) error {
+			if _, err := tx.CreateBucketIfNotExists(metaBucketName); err != nil {
+				return err
+			}
+			return migrate(tx)
+		}); err != nil {
+			t.Fatalf("migrate() received an error: %v", err)
+		}
+	}
+
+	if err := db.View(func(tx *bolt.Tx) error {
+		if got, want := string(tx.Bucket(metaBucketName).Get(schemaVersionKey)), strconv.Itoa(len(migrations)); got != want {
+			t.Errorf("schema version: want %s, got %q", want, got)
+		}
+		var task Task
+		if err := json.Unmarshal(tx.Bucket(tasksBucketName).Get(itob(2)), &task); err != nil {
+			return err
+		}
+		if task.Details != "buy milk" || !task.Completed || task.Priority != PriorityNone || task.Due != nil ||
+			task.UID == "" || task.UpdatedAt == nil {
+			t.Errorf("task 2: got %+v", task)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(migrateDBCmd)
+}
+
+var migrateDBCmd = &cobra.Command{
+	Use:   "migrate-db [path]",
+	Short: "Move the tasks kept in ./tasks.db, or path, to the task database",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if rootFlags.backend != backendBolt {
+			exitf("Only a %s database can be moved\n", backendBolt)
+		}
+		legacy := legacyDBPath
+		if len(args) > 0 {
+			legacy = args[0]
+		}
+		path := rootFlags.db
+		if path == "" {
+			var err error
+			if path, err = defaultDBPath(backendBolt); err != nil {
+				exitf("%v\n", err)
+			}
+		}
+		if err := moveLegacyDB(legacy, path); err != nil {
+			exitf("%v\n", err)
+		}
+		fmt.Printf("Your tasks moved from %s to %s.\n", legacy, path)
+	},
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The list of frequencies a task can repeat at
+const (
+	freqDaily   = "daily"
+	freqWeekly  = "weekly"
+	freqMonthly = "monthly"
+)
+
+var everyRegexp = regexp.MustCompile(`^every (\d+) (day|week|month)s?$`)
+
+// Recurrence is the rule a repeating task follows
+type Recurrence struct {
+	Freq string `json:"freq"`
+	// Interval repeats the task every Interval days, weeks or months
+	Interval int `json:"interval"`
+	// Weekdays, if set, repeats a weekly task on these days of the week
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+	// MonthDay is the day of the month a monthly task is due on, or the last
+	// day of the month in shorter months
+	MonthDay int `json:"month_day,omitempty"`
+}
+
+// parseRecurrence parses a recurrence rule, as in:
+//
+//	daily, weekly, monthly, weekdays
+//	every day, every week, every month
+//	every 3 days, every 2 weeks, every 6 months
+//	weekly on mon,thu, every monday and friday
+func parseRecurrence(s string) (*Recurrence, error) {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+
+	switch s {
+	case "daily", "every day":
+		return &Recurrence{Freq: freqDaily, Interval: 1}, nil
+	case "weekly", "every week":
+		return &Recurrence{Freq: freqWeekly, Interval: 1}, nil
+	case "monthly", "every month":
+		return &Recurrence{Freq: freqMonthly, Interval: 1}, nil
+	case "weekdays", "every weekday":
+		return &Recurrence{Freq: freqWeekly, Interval: 1, Weekdays: []time.Weekday{
+			time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
+		}}, nil
+	}
+
+	if m := everyRegexp.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid interval in %q", s)
+		}
+		freq := map[string]string{"day": freqDaily, "week": freqWeekly, "month": freqMonthly}[m[2]]
+		return &Recurrence{Freq: freq, Interval: n}, nil
+	}
+
+	var days string
+	switch {
+	case strings.HasPrefix(s, "weekly on "):
+		days = strings.TrimPrefix(s, "weekly on ")
+	case strings.HasPrefix(s, "every "):
+		days = strings.TrimPrefix(s, "every ")
+	default:
+		return nil, fmt.Errorf("unrecognized recurrence %q", s)
+	}
+	r := &Recurrence{Freq: freqWeekly, Interval: 1}
+	seen := map[time.Weekday]bool{}
+	for _, day := range strings.FieldsFunc(days, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if day == "and" {
+			continue
+		}
+		wd, ok := weekdays[day]
+		if !ok {
+			return nil, fmt.Errorf("unrecognized recurrence %q, %q isn't a day of the week", s, day)
+		}
+		if !seen[wd] {
+			seen[wd] = true
+			r.Weekdays = append(r.Weekdays, wd)
+		}
+	}
+	if len(r.Weekdays) == 0 {
+		return nil, fmt.Errorf("unrecognized recurrence %q", s)
+	}
+	sort.Slice(r.Weekdays, func(i, j int) bool { return r.Weekdays[i] < r.Weekdays[j] })
+	return r, nil
+}
+
+func (r *Recurrence) String() string {
+	var unit string
+	switch r.Freq {
+	case freqDaily:
+		unit = "day"
+	case freqWeekly:
+		unit = "week"
+	case freqMonthly:
+		unit = "month"
+	}
+
+	if len(r.Weekdays) > 0 {
+		var days []string
+		for _, wd := range r.Weekdays {
+			days = append(days, strings.ToLower(wd.String()[:3]))
+		}
+		return "weekly on " + strings.Join(days, ",")
+	}
+	if r.Interval > 1 {
+		return fmt.Sprintf("every %d %ss", r.Interval, unit)
+	}
+	return r.Freq
+}
+
+// First returns the first day on or after from the task is due on
+func (r *Recurrence) First(from time.Time) time.Time {
+	from = startOfDay(from)
+	if len(r.Weekdays) > 0 && !r.on(from.Weekday()) {
+		return r.Next(from)
+	}
+	return from
+}
+
+// Next returns the day the task is due on after the one due on from
+func (r *Recurrence) Next(from time.Time) time.Time {
+	from = startOfDay(from)
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch r.Freq {
+	case freqDaily:
+		return from.AddDate(0, 0, interval)
+	case freqWeekly:
+		if len(r.Weekdays) == 0 {
+			return from.AddDate(0, 0, 7*interval)
+		}
+		next := from.AddDate(0, 0, 1)
+		for !r.on(next.Weekday()) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	case freqMonthly:
+		day := r.MonthDay
+		if day == 0 {
+			day = from.Day()
+		}
+		// the first of the month can't overflow into the next one
+		month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()).AddDate(0, interval, 0)
+		if last := month.AddDate(0, 1, -1).Day(); day > last {
+			day = last
+		}
+		return month.AddDate(0, 0, day-1)
+	default:
+		return from.AddDate(0, 0, interval)
+	}
+}
+
+// NextAfter returns the first day the task is due on after the one due on
+// from that isn't before today, skipping the occurrences that were missed
+func (r *Recurrence) NextAfter(from, now time.Time) time.Time {
+	today := startOfDay(now)
+	next := r.Next(from)
+	for next.Before(today) {
+		next = r.Next(next)
+	}
+	return next
+}
+
+func (r *Recurrence) on(wd time.Weekday) bool {
+	for _, d := range r.Weekdays {
+		if d == wd {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"daily", "daily"},
+		{"Every Day", "daily"},
+		{"weekly", "weekly"},
+		{"monthly", "monthly"},
+		{"every 3 days", "every 3 days"},
+		{"every 1 week", "weekly"},
+		{"every 2 weeks", "every 2 weeks"},
+		{"every 6 months", "every 6 months"},
+		{"weekdays", "weekly on mon,tue,wed,thu,fri"},
+		{"weekly on thu,mon", "weekly on mon,thu"},
+		{"every monday and friday", "weekly on mon,fri"},
+		{"every sat, sun", "weekly on sun,sat"},
+	}
+	for _, c := range cases {
+		r, err := parseRecurrence(c.in)
+		if err != nil {
+			t.Fatalf("parseRecurrence(%q) received an error: %v", c.in, err)
+		}
+		if got := r.String(); got != c.want {
+			t.Errorf("parseRecurrence(%q): want %q, got %q", c.in, c.want, got)
+		}
+	}
+
+	for _, in := range []string{"", "sometimes", "every", "every 0 days", "weekly on someday", "every year"} {
+		if _, err := parseRecurrence(in); err == nil {
+			t.Errorf("parseRecurrence(%q): expected an error", in)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse(dateLayout, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	cases := []struct {
+		rule string
+		from string
+		want []string
+	}{
+		{"daily", "2020-05-06", []string{"2020-05-07", "2020-05-08"}},
+		{"every 3 days", "2020-05-06", []string{"2020-05-09", "2020-05-12"}},
+		{"every 2 weeks", "2020-05-06", []string{"2020-05-20", "2020-06-03"}},
+		// a Wednesday
+		{"weekly on mon,thu", "2020-05-06", []string{"2020-05-07", "2020-05-11", "2020-05-14"}},
+		{"weekdays", "2020-05-08", []string{"2020-05-11", "2020-05-12"}},
+		{"monthly", "2020-01-31", []string{"2020-02-29", "2020-03-31", "2020-04-30"}},
+		{"every 3 months", "2020-11-15", []string{"2021-02-15", "2021-05-15"}},
+	}
+	for _, c := range cases {
+		r, err := parseRecurrence(c.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Freq == freqMonthly {
+			r.MonthDay = date(c.from).Day()
+		}
+		next := date(c.from)
+		for _, want := range c.want {
+			next = r.Next(next)
+			if got := next.Format(dateLayout); got != want {
+				t.Errorf("%s: want %s, got %s", c.rule, want, got)
+				break
+			}
+		}
+	}
+
+	r, _ := parseRecurrence("weekly on mon,thu")
+	if got := r.First(date("2020-05-07")).Format(dateLayout); got != "2020-05-07" {
+		t.Errorf("First(): want 2020-05-07, got %s", got)
+	}
+	if got := r.First(date("2020-05-08")).Format(dateLayout); got != "2020-05-11" {
+		t.Errorf("First(): want 2020-05-11, got %s", got)
+	}
+	// missed occurrences are skipped
+	if got := r.NextAfter(date("2020-04-02"), date("2020-05-06").Add(15*time.Hour)).Format(dateLayout); got != "2020-05-07" {
+		t.Errorf("NextAfter(): want 2020-05-07, got %s", got)
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(archiveCmd)
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <id>...",
+	Short: "Restore deleted tasks from the archive",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			exitf("Missing task ID\n")
+		}
+		ids, err := parseIDs(args)
+		if err != nil {
+			exitf("%v\n", err)
+		}
+		tasks := tasksWithIDs(ids)
+		if err := RestoreTasks(tasks...); err != nil {
+			exitf("%v\n", err)
+		}
+		for _, task := range tasks {
+			fmt.Printf("You have restored the %q task.\n", task.Details)
+		}
+	},
+}
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "List your deleted tasks",
+	Run: func(cmd *cobra.Command, _ []string) {
+		tasks, err := ListArchivedTasks()
+		if err != nil {
+			exitf("%v\n", err)
+		}
+
+		if len(tasks) == 0 {
+			fmt.Println("You don't have any deleted tasks.")
+			os.Exit(0)
+		}
+
+		fmt.Println("You have deleted the following tasks:")
+		for _, t := range tasks {
+			fmt.Printf("%d. %s (deleted: %s)\n", t.ID, t.Details, t.DeletedAt.Format("2006-01-02 15:04"))
+		}
+	},
+}
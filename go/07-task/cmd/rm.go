@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"strconv"
 
 	"github.com/spf13/cobra"
 )
@@ -12,20 +11,23 @@ func init() {
 }
 
 var rmCmd = &cobra.Command{
-	Use:   "rm",
-	Short: "Delete a task from your TODO list",
+	Use:   "rm <id>...",
+	Short: "Delete tasks from your TODO list",
+	Long:  "Delete tasks from your TODO list, given as IDs or ranges of them. Deleted tasks are moved to the archive, and can be restored with \"task restore\".",
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			exitf("Missing task ID\n")
 		}
-		id, err := strconv.Atoi(args[0])
+		ids, err := parseIDs(args)
 		if err != nil {
 			exitf("%v\n", err)
 		}
-		task := Task{ID: id}
-		if err := DeleteTask(&task); err != nil {
+		tasks := tasksWithIDs(ids)
+		if err := DeleteTasks(tasks...); err != nil {
 			exitf("%v\n", err)
 		}
-		fmt.Printf("You have deleted the %q task.\n", task.Details)
+		for _, task := range tasks {
+			fmt.Printf("You have deleted the %q task.\n", task.Details)
+		}
 	},
 }
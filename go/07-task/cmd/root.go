@@ -12,12 +12,28 @@ var rootCmd = &cobra.Command{
 	Short: "task is a CLI for managing your TODOs.",
 }
 
-// func init() {
-// 	rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
-// }
+var rootFlags struct {
+	db      string
+	backend string
+}
+
+func init() {
+	// rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+
+	backend := os.Getenv("TASK_BACKEND")
+	if backend == "" {
+		backend = backendBolt
+	}
+	rootCmd.PersistentFlags().StringVar(&rootFlags.db, "db", os.Getenv("TASK_DB"), "path of the task database, defaults to $XDG_DATA_HOME/task/tasks.db (env TASK_DB)")
+	rootCmd.PersistentFlags().StringVar(&rootFlags.backend, "backend", backend, "storage backend, bolt or sqlite (env TASK_BACKEND)")
+}
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if cerr := closeStore(); err == nil {
+		err = cerr
+	}
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -25,5 +41,6 @@ func Execute() {
 
 func exitf(format string, a ...interface{}) {
 	fmt.Printf(format, a...)
+	closeStore()
 	os.Exit(1)
 }
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var serveFlags struct {
+	addr  string
+	token string
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveFlags.addr, "addr", ":8080", "address to listen on")
+	serveCmd.Flags().StringVar(&serveFlags.token, "token", os.Getenv("TASK_SYNC_TOKEN"), "token clients have to send, if any (env TASK_SYNC_TOKEN)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a sync server for your machines to sync tasks with",
+	Long: `Run a sync server for your machines to sync tasks with, using "task sync".
+
+The server keeps its tasks in a database of its own, sync-tasks.db next to the
+default one, unless told otherwise with --db.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		path := rootFlags.db
+		if path == "" {
+			p, err := defaultDBPath(rootFlags.backend)
+			if err != nil {
+				exitf("%v\n", err)
+			}
+			path = filepath.Join(filepath.Dir(p), "sync-"+filepath.Base(p))
+		}
+		s, err := OpenStore(rootFlags.backend, path)
+		if err != nil {
+			exitf("%v\n", err)
+		}
+		defaultStore = s
+
+		if serveFlags.token == "" {
+			log.Printf("No --token given, so anyone who can reach the server can sync with it")
+		}
+		log.Printf("Serving the tasks in %s on %s", path, serveFlags.addr)
+		if err := http.ListenAndServe(serveFlags.addr, newSyncServer(s, serveFlags.token)); err != nil {
+			exitf("%v\n", err)
+		}
+	},
+}
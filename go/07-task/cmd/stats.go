@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show your completion streaks and throughput",
+	Run: func(cmd *cobra.Command, _ []string) {
+		incomplete, err := ListTasks(false)
+		if err != nil {
+			exitf("%v\n", err)
+		}
+		completed, err := ListTasks(true)
+		if err != nil {
+			exitf("%v\n", err)
+		}
+
+		s := computeStats(append(incomplete, completed...), time.Now())
+		fmt.Printf("Open tasks:       %d (%d overdue)\n", s.Open, s.Overdue)
+		fmt.Printf("Completed:        %d today, %d this week, %d in total\n", s.CompletedToday, s.CompletedThisWeek, s.Completed)
+		fmt.Printf("Throughput:       %.1f tasks/day over the last %d days\n", s.Throughput, throughputDays)
+		if s.CycleTime > 0 {
+			fmt.Printf("Time to complete: %s on average\n", formatDays(s.CycleTime))
+		}
+		fmt.Printf("Current streak:   %s\n", pluralize(s.CurrentStreak, "day"))
+		fmt.Printf("Longest streak:   %s\n", pluralize(s.LongestStreak, "day"))
+	},
+}
+
+// throughputDays is the number of days, today included, the throughput is
+// averaged over
+const throughputDays = 30
+
+type taskStats struct {
+	Open, Overdue                                int
+	Completed, CompletedToday, CompletedThisWeek int
+	// Throughput is the average number of tasks completed per day
+	Throughput float64
+	// CycleTime is the average time between creating and completing a task
+	CycleTime time.Duration
+	// CurrentStreak is the number of consecutive days with completed tasks,
+	// ending today, or yesterday if nothing is completed today yet
+	CurrentStreak, LongestStreak int
+}
+
+// computeStats computes the stats of tasks as of now. Completed tasks with an
+// unknown completion time only count towards the total.
+func computeStats(tasks []*Task, now time.Time) taskStats {
+	var s taskStats
+	today, week := startOfDay(now), startOfWeek(now)
+	since := today.AddDate(0, 0, -(throughputDays - 1))
+
+	days := map[time.Time]bool{}
+	var recent, cycles int
+	var cycleTime time.Duration
+	for _, t := range tasks {
+		if !t.Completed {
+			s.Open++
+			if t.Overdue(now) {
+				s.Overdue++
+			}
+			continue
+		}
+
+		s.Completed++
+		if t.CompletedAt == nil {
+			continue
+		}
+		at := t.CompletedAt.In(now.Location())
+		days[startOfDay(at)] = true
+		if !at.Before(today) {
+			s.CompletedToday++
+		}
+		if !at.Before(week) {
+			s.CompletedThisWeek++
+		}
+		if !at.Before(since) {
+			recent++
+		}
+		if t.CreatedAt != nil {
+			cycleTime += t.CompletedAt.Sub(*t.CreatedAt)
+			cycles++
+		}
+	}
+
+	s.Throughput = float64(recent) / throughputDays
+	if cycles > 0 {
+		s.CycleTime = cycleTime / time.Duration(cycles)
+	}
+
+	day := today
+	if !days[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day] {
+		s.CurrentStreak++
+		day = day.AddDate(0, 0, -1)
+	}
+	for d := range days {
+		// only count the streaks starting on d
+		if days[d.AddDate(0, 0, -1)] {
+			continue
+		}
+		n := 0
+		for days[d.AddDate(0, 0, n)] {
+			n++
+		}
+		if n > s.LongestStreak {
+			s.LongestStreak = n
+		}
+	}
+	return s
+}
+
+func formatDays(d time.Duration) string {
+	if d < 24*time.Hour {
+		return d.Round(time.Minute).String()
+	}
+	return fmt.Sprintf("%.1f days", d.Hours()/24)
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
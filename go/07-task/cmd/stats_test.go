@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeStats(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2020, time.May, 6, 18, 0, 0, 0, time.UTC)
+	at := func(d, h int) *time.Time {
+		t := time.Date(2020, time.May, d, h, 0, 0, 0, time.UTC)
+		return &t
+	}
+	tasks := []*Task{
+		{ID: 1, Due: at(1, 0)},
+		{ID: 2},
+		{ID: 3, Completed: true},
+		{ID: 4, Completed: true, CreatedAt: at(1, 10), CompletedAt: at(1, 12)},
+		{ID: 5, Completed: true, CreatedAt: at(1, 10), CompletedAt: at(2, 10)},
+		{ID: 6, Completed: true, CompletedAt: at(3, 9)},
+		{ID: 7, Completed: true, CreatedAt: at(4, 10), CompletedAt: at(5, 10)},
+		{ID: 8, Completed: true, CompletedAt: at(5, 23)},
+	}
+
+	s := computeStats(tasks, now)
+	if s.Open != 2 || s.Overdue != 1 {
+		t.Errorf("open: want 2 (1 overdue), got %d (%d overdue)", s.Open, s.Overdue)
+	}
+	if s.Completed != 6 || s.CompletedToday != 0 || s.CompletedThisWeek != 2 {
+		t.Errorf("completed: want 6, 0 today and 2 this week, got %d, %d and %d", s.Completed, s.CompletedToday, s.CompletedThisWeek)
+	}
+	if want := 5.0 / throughputDays; s.Throughput != want {
+		t.Errorf("throughput: want %f, got %f", want, s.Throughput)
+	}
+	if want := (2*time.Hour + 48*time.Hour) / 3; s.CycleTime != want {
+		t.Errorf("cycle time: want %v, got %v", want, s.CycleTime)
+	}
+	// May 3rd is followed by a day without completions
+	if s.CurrentStreak != 1 || s.LongestStreak != 3 {
+		t.Errorf("streaks: want 1 and 3, got %d and %d", s.CurrentStreak, s.LongestStreak)
+	}
+
+	tasks = append(tasks, &Task{ID: 9, Completed: true, CompletedAt: at(4, 8)}, &Task{ID: 10, Completed: true, CompletedAt: at(6, 8)})
+	s = computeStats(tasks, now)
+	if s.CompletedToday != 1 || s.CurrentStreak != 6 || s.LongestStreak != 6 {
+		t.Errorf("want 1 completed today and streaks of 6, got %d, %d and %d", s.CompletedToday, s.CurrentStreak, s.LongestStreak)
+	}
+}
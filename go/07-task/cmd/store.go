@@ -1,128 +1,766 @@
 package cmd
 
 import (
-	"encoding/binary"
-	"encoding/json"
 	"fmt"
-
-	"github.com/boltdb/bolt"
+	"os"
+	"path/filepath"
+	"time"
 )
 
+// The list of backends a Store can be kept in
 const (
-	dbFileName = "tasks.db"
+	backendBolt   = "bolt"
+	backendSQLite = "sqlite"
 )
 
-var (
-	tasksBucketName = []byte("tasks")
+// maxOps is the number of operations kept around to undo
+const maxOps = 100
+
+// Store keeps the tasks. Every change to them is recorded as an Op, so it can
+// be undone.
+type Store interface {
+	// CreateTask assigns task a new ID and saves it
+	CreateTask(task *Task) error
+	// ListTasks returns either the completed or the incomplete tasks, by ID,
+	// leaving out the deleted ones
+	ListTasks(completed bool) ([]*Task, error)
+	// GetTask returns the task with id, unless it's deleted
+	GetTask(id int) (*Task, error)
+	// UpdateTask replaces the task with task.ID with task
+	UpdateTask(task *Task) error
+	// MarkTasksAsCompleted marks the tasks with the IDs of tasks as
+	// completed, loads them into tasks, and returns how each of them was
+	// completed. It fails without changing any of them if one doesn't
+	// exist.
+	MarkTasksAsCompleted(tasks ...*Task) ([]Completion, error)
+	// ReopenTasks marks the completed tasks with the IDs of tasks as
+	// incomplete, and loads them into tasks. The next occurrences completing
+	// them spawned are taken back, unless they're completed or deleted.
+	ReopenTasks(tasks ...*Task) error
+	// DeleteTasks moves the tasks with the IDs of tasks to the archive, and
+	// loads them into tasks
+	DeleteTasks(tasks ...*Task) error
+	// ListArchivedTasks returns the deleted tasks, by ID, except the purged
+	// ones
+	ListArchivedTasks() ([]*Task, error)
+	// RestoreTasks moves the tasks with the IDs of tasks back from the
+	// archive, and loads them into tasks
+	RestoreTasks(tasks ...*Task) error
+	// ImportTasks saves tasks, keeping their IDs unless they're 0 or taken,
+	// in which case they're assigned new ones
+	ImportTasks(tasks ...*Task) error
+	// ListAllTasks returns every task, completed and deleted ones included,
+	// by ID
+	ListAllTasks() ([]*Task, error)
+	// MergeTasks saves the tasks that are newer than the ones with the same
+	// UIDs, keeping their IDs if they're new and the IDs are free, and
+	// returns the ones it saved. Merging can't be undone, and clears what
+	// could be undone before it if it saved anything.
+	MergeTasks(tasks ...*Task) ([]*Task, error)
+	// Meta returns the value of key, or "" if it's not set, and SetMeta sets
+	// it
+	Meta(key string) (string, error)
+	SetMeta(key, value string) error
+	// Undo reverts the last operation and returns it, or returns nil if
+	// there's nothing left to undo
+	Undo() (*Op, error)
+	Close() error
+}
+
+// The list of kinds of operations on tasks
+const (
+	opAdd     = "add"
+	opEdit    = "edit"
+	opDo      = "do"
+	opReopen  = "reopen"
+	opRm      = "rm"
+	opRestore = "restore"
+	opImport  = "import"
 )
 
-type Task struct {
-	ID        int    `json:"id"`
-	Details   string `json:"details"`
-	Completed bool   `json:"completed"`
+// Completion is the outcome of marking a task as completed
+type Completion struct {
+	// AlreadyCompleted is whether the task was completed before, in which
+	// case nothing changed
+	AlreadyCompleted bool
+	// Next is the next occurrence of a recurring task, spawned by
+	// completing it
+	Next *Task
 }
 
-func CreateTask(task *Task) error {
-	return withDB(func(db *bolt.DB) error {
-		return db.Update(func(tx *bolt.Tx) error {
-			bucket := tx.Bucket(tasksBucketName)
+// Op is an operation that changed one or more tasks
+type Op struct {
+	ID      int        `json:"id"`
+	Kind    string     `json:"kind"`
+	Time    time.Time  `json:"time"`
+	Changes []OpChange `json:"changes"`
+}
 
-			id, err := bucket.NextSequence()
-			if err != nil {
-				return err
+// OpChange is the change of a single task in an Op
+type OpChange struct {
+	ID int `json:"id"`
+	// Before is the task as it was before the op, or nil if the op created it
+	Before *Task `json:"before"`
+}
+
+// storeTx is a transaction of a store backend, working with every task,
+// including the deleted ones
+type storeTx interface {
+	// NextTaskID returns an ID no task has ever had
+	NextTaskID() (int, error)
+	// ReserveTaskID makes sure NextTaskID never returns id, or any ID before
+	// it
+	ReserveTaskID(id int) error
+	// GetTask returns the task with id, or errTaskNotFound
+	GetTask(id int) (*Task, error)
+	PutTask(task *Task) error
+	// ForEachTask calls fn with every task, by ID
+	ForEachTask(fn func(*Task) error) error
+
+	// GetMeta returns the value of key, or "" if it's not set
+	GetMeta(key string) (string, error)
+	PutMeta(key, value string) error
+
+	// PushOp assigns op an ID and appends it to the log, trimming it to the
+	// last maxOps operations
+	PushOp(op *Op) error
+	// PopOp removes the last op of the log and returns it, or returns nil if
+	// the log is empty
+	PopOp() (*Op, error)
+}
+
+// storeBackend runs transactions against the place tasks are kept in
+type storeBackend interface {
+	View(fn func(storeTx) error) error
+	Update(fn func(storeTx) error) error
+	Close() error
+}
+
+// OpenStore opens, or creates, the store of the given backend at path
+func OpenStore(backend, path string) (Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	var b storeBackend
+	var err error
+	switch backend {
+	case backendBolt:
+		b, err = openBoltBackend(path)
+	case backendSQLite:
+		b, err = openSQLiteBackend(path)
+	default:
+		return nil, fmt.Errorf("unknown backend %q, expected %s or %s", backend, backendBolt, backendSQLite)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &store{b: b}, nil
+}
+
+// store implements Store over any backend
+type store struct {
+	b storeBackend
+}
+
+func (s *store) Close() error {
+	return s.b.Close()
+}
+
+func (s *store) CreateTask(task *Task) error {
+	return s.b.Update(func(tx storeTx) error {
+		op := &Op{Kind: opAdd, Time: time.Now()}
+		if err := createTask(tx, op, task); err != nil {
+			return err
+		}
+		return tx.PushOp(op)
+	})
+}
+
+// createTask assigns task a new ID and saves it as part of op
+func createTask(tx storeTx, op *Op, task *Task) error {
+	id, err := tx.NextTaskID()
+	if err != nil {
+		return err
+	}
+	task.ID = id
+	if task.CreatedAt == nil {
+		now := time.Now()
+		task.CreatedAt = &now
+	}
+	task.touch(time.Now())
+
+	if err := tx.PutTask(task); err != nil {
+		return err
+	}
+	op.Changes = append(op.Changes, OpChange{ID: id})
+	return nil
+}
+
+func (s *store) ListTasks(completed bool) ([]*Task, error) {
+	var tasks []*Task
+	err := s.b.View(func(tx storeTx) error {
+		return tx.ForEachTask(func(task *Task) error {
+			if task.DeletedAt == nil && task.Completed == completed {
+				tasks = append(tasks, task)
 			}
-			task.ID = int(id)
+			return nil
+		})
+	})
+	return tasks, err
+}
+
+func (s *store) GetTask(id int) (*Task, error) {
+	var task *Task
+	err := s.b.View(func(tx storeTx) error {
+		var err error
+		task, err = tx.GetTask(id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if task.DeletedAt != nil {
+		return nil, errTaskNotFound(id)
+	}
+	return task, nil
+}
 
-			b, err := json.Marshal(&task)
+func (s *store) ListArchivedTasks() ([]*Task, error) {
+	var tasks []*Task
+	err := s.b.View(func(tx storeTx) error {
+		return tx.ForEachTask(func(task *Task) error {
+			if task.DeletedAt != nil && !task.Purged {
+				tasks = append(tasks, task)
+			}
+			return nil
+		})
+	})
+	return tasks, err
+}
+
+func (s *store) UpdateTask(task *Task) error {
+	return s.change(opEdit, []*Task{task}, func(_ storeTx, _ *Op, current *Task) error {
+		if current.DeletedAt != nil {
+			return errTaskNotFound(current.ID)
+		}
+		// the task is still the same one on other machines
+		uid := current.UID
+		*current = *task
+		current.UID = uid
+		return nil
+	})
+}
+
+func (s *store) MarkTasksAsCompleted(tasks ...*Task) ([]Completion, error) {
+	completions := map[int]Completion{}
+	err := s.change(opDo, tasks, func(tx storeTx, op *Op, task *Task) error {
+		if task.DeletedAt != nil {
+			return errTaskNotFound(task.ID)
+		}
+		if task.Completed {
+			completions[task.ID] = Completion{AlreadyCompleted: true}
+			return nil
+		}
+		now := time.Now()
+		task.Completed = true
+		task.CompletedAt = &now
+		next := task.nextOccurrence(now)
+		if next == nil {
+			return nil
+		}
+		completions[task.ID] = Completion{Next: next}
+		if err := createTask(tx, op, next); err != nil {
+			return err
+		}
+		task.NextUID = next.UID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Completion, len(tasks))
+	for i, task := range tasks {
+		result[i] = completions[task.ID]
+	}
+	return result, nil
+}
+
+func (s *store) ReopenTasks(tasks ...*Task) error {
+	return s.change(opReopen, tasks, func(tx storeTx, op *Op, task *Task) error {
+		if task.DeletedAt != nil {
+			return errTaskNotFound(task.ID)
+		}
+		if !task.Completed {
+			return fmt.Errorf("task %d isn't completed", task.ID)
+		}
+		task.Completed = false
+		task.CompletedAt = nil
+		next := task.NextUID
+		task.NextUID = ""
+		if next == "" {
+			return nil
+		}
+		return purgeOccurrence(tx, op, next)
+	})
+}
+
+// purgeOccurrence takes back the occurrence with uid as part of op, unless
+// it's completed or deleted already
+func purgeOccurrence(tx storeTx, op *Op, uid string) error {
+	var next *Task
+	err := tx.ForEachTask(func(task *Task) error {
+		if task.UID == uid {
+			next = task
+		}
+		return nil
+	})
+	if err != nil || next == nil || next.Completed || next.DeletedAt != nil {
+		return err
+	}
+	before := *next
+	next.DeletedAt = &op.Time
+	next.Purged = true
+	next.touch(op.Time)
+	if err := tx.PutTask(next); err != nil {
+		return err
+	}
+	op.Changes = append(op.Changes, OpChange{ID: next.ID, Before: &before})
+	return nil
+}
+
+func (s *store) DeleteTasks(tasks ...*Task) error {
+	return s.change(opRm, tasks, func(_ storeTx, _ *Op, task *Task) error {
+		if task.DeletedAt != nil {
+			return errTaskNotFound(task.ID)
+		}
+		now := time.Now()
+		task.DeletedAt = &now
+		return nil
+	})
+}
+
+func (s *store) RestoreTasks(tasks ...*Task) error {
+	return s.change(opRestore, tasks, func(_ storeTx, _ *Op, task *Task) error {
+		if task.Purged {
+			return errTaskNotFound(task.ID)
+		}
+		if task.DeletedAt == nil {
+			return fmt.Errorf("task %d isn't deleted", task.ID)
+		}
+		task.DeletedAt = nil
+		return nil
+	})
+}
+
+// change loads the tasks with the IDs of tasks, passes each of them to fn,
+// and saves them back, recording them as an op of kind, which fn may add
+// more changes to. Either all of the tasks are changed or none of them are.
+func (s *store) change(kind string, tasks []*Task, fn func(storeTx, *Op, *Task) error) error {
+	return s.b.Update(func(tx storeTx) error {
+		op := &Op{Kind: kind, Time: time.Now()}
+		for _, task := range tasks {
+			before, err := tx.GetTask(task.ID)
 			if err != nil {
 				return err
 			}
+			after := *before
+			if err := fn(tx, op, &after); err != nil {
+				return err
+			}
+			after.ID = before.ID
+			after.touch(op.Time)
+			if err := tx.PutTask(&after); err != nil {
+				return err
+			}
+			*task = after
+			op.Changes = append(op.Changes, OpChange{ID: task.ID, Before: before})
+		}
+		return tx.PushOp(op)
+	})
+}
 
-			return bucket.Put(itob(task.ID), b)
-		})
+func (s *store) ImportTasks(tasks ...*Task) error {
+	return s.b.Update(func(tx storeTx) error {
+		op := &Op{Kind: opImport, Time: time.Now()}
+		for _, task := range tasks {
+			// an imported task is a new one, even if it came from another
+			// machine
+			task.UID = ""
+			task.touch(op.Time)
+		}
+		if err := insertTasks(tx, op, tasks); err != nil {
+			return err
+		}
+		return tx.PushOp(op)
 	})
 }
 
-func ListTasks(completed bool) ([]*Task, error) {
-	var tasks []*Task
-	return tasks, withDB(func(db *bolt.DB) error {
-		return db.View(func(tx *bolt.Tx) error {
-			bucket := tx.Bucket(tasksBucketName)
+// insertTasks saves tasks as new ones, keeping their IDs unless they're 0 or
+// taken, in which case they're assigned new ones
+func insertTasks(tx storeTx, op *Op, tasks []*Task) error {
+	// place the tasks with free IDs first, so the new IDs of the others
+	// don't take them
+	var rest []*Task
+	for _, task := range tasks {
+		if task.CreatedAt == nil {
+			now := time.Now()
+			task.CreatedAt = &now
+		}
+		if task.ID <= 0 {
+			rest = append(rest, task)
+			continue
+		}
+		_, err := tx.GetTask(task.ID)
+		if err == nil {
+			rest = append(rest, task)
+			continue
+		}
+		if _, ok := err.(taskNotFoundError); !ok {
+			return err
+		}
 
-			return bucket.ForEach(func(_, b []byte) error {
-				var task Task
-				if err := json.Unmarshal(b, &task); err != nil {
-					return err
-				}
-				if task.Completed != completed {
-					return nil
-				}
-				tasks = append(tasks, &task)
-				return nil
-			})
+		if err := tx.ReserveTaskID(task.ID); err != nil {
+			return err
+		}
+		if err := tx.PutTask(task); err != nil {
+			return err
+		}
+		op.Changes = append(op.Changes, OpChange{ID: task.ID})
+	}
+
+	for _, task := range rest {
+		id, err := tx.NextTaskID()
+		if err != nil {
+			return err
+		}
+		task.ID = id
+		if err := tx.PutTask(task); err != nil {
+			return err
+		}
+		op.Changes = append(op.Changes, OpChange{ID: id})
+	}
+	return nil
+}
+
+func (s *store) ListAllTasks() ([]*Task, error) {
+	var tasks []*Task
+	err := s.b.View(func(tx storeTx) error {
+		return tx.ForEachTask(func(task *Task) error {
+			tasks = append(tasks, task)
+			return nil
 		})
 	})
+	return tasks, err
 }
 
-func MarkTaskAsCompleted(task *Task) error {
-	return withDB(func(db *bolt.DB) error {
-		return db.Update(func(tx *bolt.Tx) error {
-			bucket := tx.Bucket(tasksBucketName)
+func (s *store) MergeTasks(tasks ...*Task) ([]*Task, error) {
+	var saved []*Task
+	err := s.b.Update(func(tx storeTx) error {
+		byUID := map[string]*Task{}
+		if err := tx.ForEachTask(func(task *Task) error {
+			if task.UID != "" {
+				byUID[task.UID] = task
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
 
-			b := bucket.Get(itob(task.ID))
-			if b == nil {
-				return fmt.Errorf("task not found with ID=%d", task.ID)
+		// merging isn't undoable, the op only collects what changed
+		op := &Op{}
+		var added []*Task
+		for _, task := range tasks {
+			if task.UID == "" {
+				return fmt.Errorf("task %d has no UID to merge it by", task.ID)
 			}
-			if err := json.Unmarshal(b, task); err != nil {
+			current, ok := byUID[task.UID]
+			if !ok {
+				added = append(added, task)
+				byUID[task.UID] = task
+				continue
+			}
+			if !task.newer(current) {
+				continue
+			}
+			// the same task came twice, and isn't saved yet
+			if current.ID == 0 {
+				*current = *task
+				continue
+			}
+
+			before := *current
+			task.ID = current.ID
+			if err := tx.PutTask(task); err != nil {
 				return err
 			}
-			task.Completed = true
-			b, err := json.Marshal(&task)
-			if err != nil {
+			*current = *task
+			op.Changes = append(op.Changes, OpChange{ID: task.ID, Before: &before})
+			saved = append(saved, task)
+		}
+		if err := insertTasks(tx, op, added); err != nil {
+			return err
+		}
+		saved = append(saved, added...)
+
+		if len(op.Changes) == 0 {
+			return nil
+		}
+		// the ops before the merge would undo the tasks to how they were
+		// before it, reverting the changes it brought on every machine
+		for {
+			op, err := tx.PopOp()
+			if err != nil || op == nil {
 				return err
 			}
-			return bucket.Put(itob(task.ID), b)
-		})
+		}
 	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
 }
 
-func DeleteTask(task *Task) error {
-	return withDB(func(db *bolt.DB) error {
-		return db.Update(func(tx *bolt.Tx) error {
-			bucket := tx.Bucket(tasksBucketName)
+func (s *store) Meta(key string) (string, error) {
+	var value string
+	err := s.b.View(func(tx storeTx) error {
+		var err error
+		value, err = tx.GetMeta(key)
+		return err
+	})
+	return value, err
+}
 
-			b := bucket.Get(itob(task.ID))
-			if b == nil {
-				return fmt.Errorf("task not found with ID=%d", task.ID)
-			}
+func (s *store) SetMeta(key, value string) error {
+	return s.b.Update(func(tx storeTx) error {
+		return tx.PutMeta(key, value)
+	})
+}
 
-			if err := json.Unmarshal(b, task); err != nil {
+func (s *store) Undo() (*Op, error) {
+	var op *Op
+	err := s.b.Update(func(tx storeTx) error {
+		var err error
+		if op, err = tx.PopOp(); err != nil || op == nil {
+			return err
+		}
+		// undo the changes in reverse, in case a task changed twice. Undoing
+		// is a change of its own, so it wins over the undone one when
+		// syncing.
+		now := time.Now()
+		for i := len(op.Changes) - 1; i >= 0; i-- {
+			c := op.Changes[i]
+			task := c.Before
+			if task == nil {
+				// the task may have been synced already, so it's purged
+				// rather than removed, for the other machines to know
+				if task, err = tx.GetTask(c.ID); err != nil {
+					return err
+				}
+				task.DeletedAt = &now
+				task.Purged = true
+			}
+			task.touch(now)
+			if err := tx.PutTask(task); err != nil {
 				return err
 			}
-			return bucket.Delete(itob(task.ID))
-		})
+		}
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// defaultDBPath returns the path of the store of backend, in
+// $XDG_DATA_HOME/task, or ~/.local/share/task if it's not set
+func defaultDBPath(backend string) (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	name := "tasks.db"
+	if backend == backendSQLite {
+		name = "tasks.sqlite"
+	}
+	return filepath.Join(dir, "task", name), nil
 }
 
-func withDB(fn func(*bolt.DB) error) error {
-	db, err := bolt.Open(dbFileName, 0600, nil)
+// legacyDBPath is where tasks were stored before they moved to
+// defaultDBPath, relative to the working directory
+const legacyDBPath = "tasks.db"
+
+// moveLegacyDB moves the BoltDB store at legacy to path. It refuses to
+// replace a store at path, or to move a file that isn't a task database.
+func moveLegacyDB(legacy, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	ok, err := isBoltTaskDB(legacy)
 	if err != nil {
 		return err
 	}
-	defer db.Close()
+	if !ok {
+		return fmt.Errorf("%s isn't a task database", legacy)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.Rename(legacy, path)
+}
 
-	if err := db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(tasksBucketName)
+// defaultStore is the store the commands work with. It's opened on first
+// use, so tests can replace it with their own.
+var defaultStore Store
+
+func currentStore() (Store, error) {
+	if defaultStore != nil {
+		return defaultStore, nil
+	}
+
+	path := rootFlags.db
+	if path == "" {
+		var err error
+		if path, err = defaultDBPath(rootFlags.backend); err != nil {
+			return nil, err
+		}
+		// tasks used to be kept in the working directory, and are only
+		// moved from there when asked to
+		if _, err := os.Stat(legacyDBPath); err == nil && rootFlags.backend == backendBolt {
+			fmt.Fprintf(os.Stderr, "Warning: ./%s isn't used anymore, your tasks are kept in %s. Run \"task migrate-db\" to move it there.\n", legacyDBPath, path)
+		}
+	}
+	s, err := OpenStore(rootFlags.backend, path)
+	if err != nil {
+		return nil, err
+	}
+	defaultStore = s
+	return s, nil
+}
+
+func closeStore() error {
+	if defaultStore == nil {
+		return nil
+	}
+	err := defaultStore.Close()
+	defaultStore = nil
+	return err
+}
+
+func CreateTask(task *Task) error {
+	s, err := currentStore()
+	if err != nil {
 		return err
-	}); err != nil {
+	}
+	return s.CreateTask(task)
+}
+
+func ListTasks(completed bool) ([]*Task, error) {
+	s, err := currentStore()
+	if err != nil {
+		return nil, err
+	}
+	return s.ListTasks(completed)
+}
+
+func GetTask(id int) (*Task, error) {
+	s, err := currentStore()
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTask(id)
+}
+
+func UpdateTask(task *Task) error {
+	s, err := currentStore()
+	if err != nil {
+		return err
+	}
+	return s.UpdateTask(task)
+}
+
+func MarkTaskAsCompleted(task *Task) error {
+	_, err := MarkTasksAsCompleted(task)
+	return err
+}
+
+func MarkTasksAsCompleted(tasks ...*Task) ([]Completion, error) {
+	s, err := currentStore()
+	if err != nil {
+		return nil, err
+	}
+	return s.MarkTasksAsCompleted(tasks...)
+}
+
+func ReopenTask(task *Task) error {
+	return ReopenTasks(task)
+}
+
+func ReopenTasks(tasks ...*Task) error {
+	s, err := currentStore()
+	if err != nil {
+		return err
+	}
+	return s.ReopenTasks(tasks...)
+}
+
+func DeleteTask(task *Task) error {
+	return DeleteTasks(task)
+}
+
+func DeleteTasks(tasks ...*Task) error {
+	s, err := currentStore()
+	if err != nil {
 		return err
 	}
+	return s.DeleteTasks(tasks...)
+}
+
+func ListArchivedTasks() ([]*Task, error) {
+	s, err := currentStore()
+	if err != nil {
+		return nil, err
+	}
+	return s.ListArchivedTasks()
+}
+
+func RestoreTasks(tasks ...*Task) error {
+	s, err := currentStore()
+	if err != nil {
+		return err
+	}
+	return s.RestoreTasks(tasks...)
+}
+
+func ImportTasks(tasks ...*Task) error {
+	s, err := currentStore()
+	if err != nil {
+		return err
+	}
+	return s.ImportTasks(tasks...)
+}
+
+func Undo() (*Op, error) {
+	s, err := currentStore()
+	if err != nil {
+		return nil, err
+	}
+	return s.Undo()
+}
+
+// taskNotFoundError is returned for the ID of a task that doesn't exist
+type taskNotFoundError int
 
-	return fn(db)
+func (id taskNotFoundError) Error() string {
+	return fmt.Sprintf("task not found with ID=%d", int(id))
 }
 
-func itob(v int) []byte {
-	b := make([]byte, 8)
-	binary.BigEndian.PutUint64(b, uint64(v))
-	return b
+func errTaskNotFound(id int) error {
+	return taskNotFoundError(id)
 }
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	tasksBucketName = []byte("tasks")
+	// opsBucketName holds the log of operations to undo, by ID
+	opsBucketName = []byte("ops")
+	// metaBucketName holds the schemaVersionKey of the tasks in
+	// tasksBucketName, along with the keys set with Store.SetMeta
+	metaBucketName   = []byte("meta")
+	schemaVersionKey = []byte("schema_version")
+)
+
+// boltBackend keeps the tasks as JSON in a BoltDB file, by ID
+type boltBackend struct {
+	db *bolt.DB
+}
+
+func openBoltBackend(path string) (*boltBackend, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{tasksBucketName, opsBucketName, metaBucketName} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return migrate(tx)
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &boltBackend{db: db}, nil
+}
+
+// isBoltTaskDB reports whether the file at path is a BoltDB file with a tasks
+// bucket, without changing it
+func isBoltTaskDB(path string) (bool, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+	var ok bool
+	err = db.View(func(tx *bolt.Tx) error {
+		ok = tx.Bucket(tasksBucketName) != nil
+		return nil
+	})
+	return ok, err
+}
+
+func (b *boltBackend) Close() error {
+	return b.db.Close()
+}
+
+func (b *boltBackend) View(fn func(storeTx) error) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+func (b *boltBackend) Update(fn func(storeTx) error) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t *boltTx) NextTaskID() (int, error) {
+	id, err := t.tx.Bucket(tasksBucketName).NextSequence()
+	return int(id), err
+}
+
+func (t *boltTx) ReserveTaskID(id int) error {
+	bucket := t.tx.Bucket(tasksBucketName)
+	if bucket.Sequence() >= uint64(id) {
+		return nil
+	}
+	return bucket.SetSequence(uint64(id))
+}
+
+func (t *boltTx) GetTask(id int) (*Task, error) {
+	b := t.tx.Bucket(tasksBucketName).Get(itob(id))
+	if b == nil {
+		return nil, errTaskNotFound(id)
+	}
+	var task Task
+	if err := json.Unmarshal(b, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (t *boltTx) PutTask(task *Task) error {
+	b, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	return t.tx.Bucket(tasksBucketName).Put(itob(task.ID), b)
+}
+
+func (t *boltTx) GetMeta(key string) (string, error) {
+	return string(t.tx.Bucket(metaBucketName).Get([]byte(key))), nil
+}
+
+func (t *boltTx) PutMeta(key, value string) error {
+	return t.tx.Bucket(metaBucketName).Put([]byte(key), []byte(value))
+}
+
+func (t *boltTx) ForEachTask(fn func(*Task) error) error {
+	return t.tx.Bucket(tasksBucketName).ForEach(func(_, b []byte) error {
+		var task Task
+		if err := json.Unmarshal(b, &task); err != nil {
+			return err
+		}
+		return fn(&task)
+	})
+}
+
+func (t *boltTx) PushOp(op *Op) error {
+	bucket := t.tx.Bucket(opsBucketName)
+
+	id, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+	op.ID = int(id)
+	b, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	if err := bucket.Put(itob(op.ID), b); err != nil {
+		return err
+	}
+
+	// deleting while iterating skips keys, so collect them first
+	var trimmed [][]byte
+	c := bucket.Cursor()
+	for k, _ := c.First(); k != nil && btoi(k) <= op.ID-maxOps; k, _ = c.Next() {
+		trimmed = append(trimmed, k)
+	}
+	for _, k := range trimmed {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *boltTx) PopOp() (*Op, error) {
+	c := t.tx.Bucket(opsBucketName).Cursor()
+	k, b := c.Last()
+	if k == nil {
+		return nil, nil
+	}
+	var op Op
+	if err := json.Unmarshal(b, &op); err != nil {
+		return nil, err
+	}
+	return &op, c.Delete()
+}
+
+func itob(v int) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(v))
+	return b
+}
+
+func btoi(b []byte) int {
+	return int(binary.BigEndian.Uint64(b))
+}
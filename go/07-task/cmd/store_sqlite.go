@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	// registers the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteMigrations bring the schema from one user_version to the next. Tasks
+// are kept as JSON, like in the Bolt backend, so only the columns that are
+// queried on need migrating.
+var sqliteMigrations = []func(tx *sql.Tx) error{
+	sqliteExec(`CREATE TABLE tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		completed INTEGER NOT NULL DEFAULT 0,
+		data TEXT NOT NULL
+	)`),
+	sqliteExec(`CREATE TABLE ops (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		data TEXT NOT NULL
+	)`),
+	sqliteExec(`CREATE TABLE meta (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`),
+	// UIDs and update times to sync by
+	func(tx *sql.Tx) error {
+		t := &sqliteTx{tx: tx}
+		var tasks []*Task
+		if err := t.ForEachTask(func(task *Task) error {
+			tasks = append(tasks, task)
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			assignUID(task)
+			if err := t.PutTask(task); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func sqliteExec(stmt string) func(*sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(stmt)
+		return err
+	}
+}
+
+// sqliteBackend keeps the tasks in a SQLite database
+type sqliteBackend struct {
+	db *sql.DB
+}
+
+func openSQLiteBackend(path string) (*sqliteBackend, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=1000")
+	if err != nil {
+		return nil, err
+	}
+	// a single connection serializes writes, which SQLite does anyway
+	db.SetMaxOpenConns(1)
+
+	b := &sqliteBackend{db: db}
+	if err := b.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *sqliteBackend) migrate() error {
+	return b.withTx(func(tx *sql.Tx) error {
+		var version int
+		if err := tx.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+			return err
+		}
+		if version > len(sqliteMigrations) {
+			return fmt.Errorf("the database has schema version %d, newer than the supported %d", version, len(sqliteMigrations))
+		}
+		for i, m := range sqliteMigrations[version:] {
+			if err := m(tx); err != nil {
+				return fmt.Errorf("migrating to schema version %d: %v", version+i+1, err)
+			}
+		}
+		// PRAGMA doesn't take parameters
+		_, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, len(sqliteMigrations)))
+		return err
+	})
+}
+
+func (b *sqliteBackend) Close() error {
+	return b.db.Close()
+}
+
+// View runs fn in a transaction too, since database/sql has no read-only
+// ones that work across drivers
+func (b *sqliteBackend) View(fn func(storeTx) error) error {
+	return b.Update(fn)
+}
+
+func (b *sqliteBackend) Update(fn func(storeTx) error) error {
+	return b.withTx(func(tx *sql.Tx) error {
+		return fn(&sqliteTx{tx: tx})
+	})
+}
+
+// withTx runs fn in a transaction, committing it if fn succeeds
+func (b *sqliteBackend) withTx(fn func(*sql.Tx) error) error {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+type sqliteTx struct {
+	tx *sql.Tx
+}
+
+// NextTaskID reserves a row for the task, which PutTask fills in. AUTOINCREMENT
+// makes sure IDs aren't reused, even after the last task is removed.
+func (t *sqliteTx) NextTaskID() (int, error) {
+	res, err := t.tx.Exec(`INSERT INTO tasks (data) VALUES ('{}')`)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// ReserveTaskID has nothing to do, since AUTOINCREMENT already skips the IDs
+// of the rows inserted with one
+func (t *sqliteTx) ReserveTaskID(id int) error {
+	return nil
+}
+
+func (t *sqliteTx) GetTask(id int) (*Task, error) {
+	var b []byte
+	err := t.tx.QueryRow(`SELECT data FROM tasks WHERE id = ?`, id).Scan(&b)
+	if err == sql.ErrNoRows {
+		return nil, errTaskNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var task Task
+	if err := json.Unmarshal(b, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (t *sqliteTx) PutTask(task *Task) error {
+	b, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.Exec(`INSERT OR REPLACE INTO tasks (id, completed, data) VALUES (?, ?, ?)`, task.ID, task.Completed, b)
+	return err
+}
+
+func (t *sqliteTx) GetMeta(key string) (string, error) {
+	var value string
+	err := t.tx.QueryRow(`SELECT value FROM meta WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+func (t *sqliteTx) PutMeta(key, value string) error {
+	_, err := t.tx.Exec(`INSERT OR REPLACE INTO meta (key, value) VALUES (?, ?)`, key, value)
+	return err
+}
+
+func (t *sqliteTx) ForEachTask(fn func(*Task) error) error {
+	rows, err := t.tx.Query(`SELECT data FROM tasks ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// read everything first, as fn may run other statements in the
+	// transaction
+	var tasks []*Task
+	for rows.Next() {
+		var b []byte
+		if err := rows.Scan(&b); err != nil {
+			return err
+		}
+		var task Task
+		if err := json.Unmarshal(b, &task); err != nil {
+			return err
+		}
+		tasks = append(tasks, &task)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, task := range tasks {
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *sqliteTx) PushOp(op *Op) error {
+	res, err := t.tx.Exec(`INSERT INTO ops (data) VALUES ('{}')`)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	op.ID = int(id)
+	b, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	if _, err := t.tx.Exec(`UPDATE ops SET data = ? WHERE id = ?`, b, op.ID); err != nil {
+		return err
+	}
+	_, err = t.tx.Exec(`DELETE FROM ops WHERE id <= ?`, op.ID-maxOps)
+	return err
+}
+
+func (t *sqliteTx) PopOp() (*Op, error) {
+	var b []byte
+	err := t.tx.QueryRow(`SELECT data FROM ops ORDER BY id DESC LIMIT 1`).Scan(&b)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var op Op
+	if err := json.Unmarshal(b, &op); err != nil {
+		return nil, err
+	}
+	_, err = t.tx.Exec(`DELETE FROM ops WHERE id = ?`, op.ID)
+	return &op, err
+}
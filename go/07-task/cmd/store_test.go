@@ -0,0 +1,445 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestStore opens a store of backend in a temporary directory, and returns
+// it along with a func to close and remove it
+func newTestStore(t *testing.T, backend string) (Store, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenStore(backend, filepath.Join(dir, "tasks.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("OpenStore(%q) received an error: %v", backend, err)
+	}
+	return s, func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestStore(t *testing.T) {
+	for _, backend := range []string{backendBolt, backendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			s, cleanup := newTestStore(t, backend)
+			defer cleanup()
+
+			for _, details := range []string{"write report", "buy milk", "call mom"} {
+				task := Task{Details: details, Priority: PriorityHigh, Tags: []string{"work"}}
+				if err := s.CreateTask(&task); err != nil {
+					t.Fatalf("CreateTask() received an error: %v", err)
+				}
+				if task.ID == 0 || task.CreatedAt == nil {
+					t.Fatalf("CreateTask(): expected an ID and a creation time, got %+v", task)
+				}
+			}
+
+			task := Task{ID: 2}
+			if _, err := s.MarkTasksAsCompleted(&task); err != nil {
+				t.Fatalf("MarkTasksAsCompleted() received an error: %v", err)
+			}
+			if task.Details != "buy milk" || task.CompletedAt == nil {
+				t.Errorf("MarkTasksAsCompleted(): expected a completed task 2, got %+v", task)
+			}
+
+			task = Task{ID: 3}
+			if err := s.DeleteTasks(&task); err != nil {
+				t.Fatalf("DeleteTasks() received an error: %v", err)
+			}
+			if task.Details != "call mom" {
+				t.Errorf("DeleteTasks(): expected task 3 to be loaded, got %+v", task)
+			}
+			if err := s.DeleteTasks(&Task{ID: 3}); err == nil {
+				t.Errorf("DeleteTasks(): expected an error for a deleted task")
+			}
+			if _, err := s.MarkTasksAsCompleted(&Task{ID: 1}, &Task{ID: 42}); err == nil {
+				t.Errorf("MarkTasksAsCompleted(): expected an error for a missing task")
+			}
+			if task, err := s.GetTask(1); err != nil || task.Completed {
+				t.Errorf("GetTask(1): expected an incomplete task after a failed batch, got %+v and %v", task, err)
+			}
+			if _, err := s.GetTask(3); err == nil {
+				t.Errorf("GetTask(3): expected an error for a deleted task")
+			}
+
+			incomplete, err := s.ListTasks(false)
+			if err != nil {
+				t.Fatalf("ListTasks(false) received an error: %v", err)
+			}
+			if len(incomplete) != 1 || incomplete[0].ID != 1 || incomplete[0].Priority != PriorityHigh || !incomplete[0].HasTag("work") {
+				t.Errorf("ListTasks(false): want task 1, got %+v", incomplete)
+			}
+			completed, err := s.ListTasks(true)
+			if err != nil {
+				t.Fatalf("ListTasks(true) received an error: %v", err)
+			}
+			if len(completed) != 1 || completed[0].ID != 2 {
+				t.Errorf("ListTasks(true): want task 2, got %+v", completed)
+			}
+
+			// IDs aren't reused
+			task = Task{Details: "walk the dog"}
+			if err := s.CreateTask(&task); err != nil {
+				t.Fatalf("CreateTask() received an error: %v", err)
+			}
+			if task.ID != 4 {
+				t.Errorf("CreateTask(): want ID 4, got %d", task.ID)
+			}
+		})
+	}
+}
+
+func TestStoreUndo(t *testing.T) {
+	for _, backend := range []string{backendBolt, backendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			s, cleanup := newTestStore(t, backend)
+			defer cleanup()
+
+			for _, details := range []string{"a", "b", "c"} {
+				if err := s.CreateTask(&Task{Details: details}); err != nil {
+					t.Fatalf("CreateTask() received an error: %v", err)
+				}
+			}
+			if _, err := s.MarkTasksAsCompleted(tasksWithIDs([]int{1, 2})...); err != nil {
+				t.Fatalf("MarkTasksAsCompleted() received an error: %v", err)
+			}
+			if err := s.UpdateTask(&Task{ID: 3, Details: "c!"}); err != nil {
+				t.Fatalf("UpdateTask() received an error: %v", err)
+			}
+			if err := s.DeleteTasks(&Task{ID: 3}); err != nil {
+				t.Fatalf("DeleteTasks() received an error: %v", err)
+			}
+
+			archived, err := s.ListArchivedTasks()
+			if err != nil || len(archived) != 1 || archived[0].Details != "c!" {
+				t.Fatalf("ListArchivedTasks(): want task 3, got %+v and %v", archived, err)
+			}
+			if err := s.RestoreTasks(&Task{ID: 3}); err != nil {
+				t.Fatalf("RestoreTasks() received an error: %v", err)
+			}
+			if err := s.RestoreTasks(&Task{ID: 3}); err == nil {
+				t.Errorf("RestoreTasks(): expected an error for a task that isn't deleted")
+			}
+
+			details := func() string {
+				var all []string
+				for _, completed := range []bool{false, true} {
+					tasks, err := s.ListTasks(completed)
+					if err != nil {
+						t.Fatalf("ListTasks() received an error: %v", err)
+					}
+					for _, task := range tasks {
+						all = append(all, fmt.Sprintf("%d:%s:%t", task.ID, task.Details, task.Completed))
+					}
+				}
+				return strings.Join(all, " ")
+			}
+
+			cases := []struct {
+				kind string
+				want string
+			}{
+				{opRestore, ""},
+				{opRm, "3:c!:false 1:a:true 2:b:true"},
+				{opEdit, "3:c:false 1:a:true 2:b:true"},
+				{opDo, "1:a:false 2:b:false 3:c:false"},
+				{opAdd, "1:a:false 2:b:false"},
+			}
+			for _, c := range cases {
+				op, err := s.Undo()
+				if err != nil {
+					t.Fatalf("Undo() received an error: %v", err)
+				}
+				if op == nil || op.Kind != c.kind {
+					t.Fatalf("Undo(): want %q, got %+v", c.kind, op)
+				}
+				if c.want != "" {
+					if got := details(); got != c.want {
+						t.Errorf("Undo() %q: want %q, got %q", c.kind, c.want, got)
+					}
+				}
+			}
+			if op, err := s.Undo(); err != nil || op == nil {
+				t.Fatalf("Undo(): want the second add, got %+v and %v", op, err)
+			}
+			if op, err := s.Undo(); err != nil || op == nil {
+				t.Fatalf("Undo(): want the first add, got %+v and %v", op, err)
+			}
+			if op, err := s.Undo(); err != nil || op != nil {
+				t.Errorf("Undo(): want nothing left, got %+v and %v", op, err)
+			}
+
+			// undone adds aren't deleted tasks to restore
+			archived, err = s.ListArchivedTasks()
+			if err != nil || len(archived) != 0 {
+				t.Errorf("ListArchivedTasks(): want no tasks after undoing the adds, got %+v and %v", archived, err)
+			}
+			if err := s.RestoreTasks(&Task{ID: 1}); err == nil {
+				t.Errorf("RestoreTasks(): expected an error for an undone add")
+			}
+		})
+	}
+}
+
+func TestStoreRecurringTasks(t *testing.T) {
+	for _, backend := range []string{backendBolt, backendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			s, cleanup := newTestStore(t, backend)
+			defer cleanup()
+
+			r, _ := parseRecurrence("every 2 days")
+			due := startOfDay(time.Now())
+			task := Task{Details: "water plants", Tags: []string{"home"}, Due: &due, Recurrence: r}
+			if err := s.CreateTask(&task); err != nil {
+				t.Fatalf("CreateTask() received an error: %v", err)
+			}
+			completions, err := s.MarkTasksAsCompleted(&Task{ID: task.ID})
+			if err != nil {
+				t.Fatalf("MarkTasksAsCompleted() received an error: %v", err)
+			}
+			if len(completions) != 1 || completions[0].AlreadyCompleted || completions[0].Next == nil {
+				t.Fatalf("MarkTasksAsCompleted(): want the next occurrence, got %+v", completions)
+			}
+
+			tasks, err := s.ListTasks(false)
+			if err != nil {
+				t.Fatalf("ListTasks() received an error: %v", err)
+			}
+			if len(tasks) != 1 {
+				t.Fatalf("ListTasks(): want the next occurrence, got %+v", tasks)
+			}
+			next := tasks[0]
+			if next.ID == task.ID || next.Details != task.Details || !next.HasTag("home") || next.Recurrence == nil ||
+				!next.Due.Equal(due.AddDate(0, 0, 2)) {
+				t.Errorf("ListTasks(): want the next occurrence due on %s, got %+v", due.AddDate(0, 0, 2).Format(dateLayout), next)
+			}
+
+			if next.ID != completions[0].Next.ID {
+				t.Errorf("MarkTasksAsCompleted(): want next occurrence %d, got %d", next.ID, completions[0].Next.ID)
+			}
+
+			// completing it again spawns nothing
+			completions, err = s.MarkTasksAsCompleted(&Task{ID: task.ID})
+			if err != nil {
+				t.Fatalf("MarkTasksAsCompleted() received an error: %v", err)
+			}
+			if len(completions) != 1 || !completions[0].AlreadyCompleted || completions[0].Next != nil {
+				t.Errorf("MarkTasksAsCompleted(): want an already completed task, got %+v", completions)
+			}
+			if _, err := s.Undo(); err != nil {
+				t.Fatalf("Undo() received an error: %v", err)
+			}
+
+			// undoing the completion takes the next occurrence back too
+			if _, err := s.Undo(); err != nil {
+				t.Fatalf("Undo() received an error: %v", err)
+			}
+			tasks, err = s.ListTasks(false)
+			if err != nil {
+				t.Fatalf("ListTasks() received an error: %v", err)
+			}
+			if len(tasks) != 1 || tasks[0].ID != task.ID {
+				t.Errorf("ListTasks(): want only task %d after undoing, got %+v", task.ID, tasks)
+			}
+		})
+	}
+}
+
+func TestStoreReopenTasks(t *testing.T) {
+	for _, backend := range []string{backendBolt, backendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			s, cleanup := newTestStore(t, backend)
+			defer cleanup()
+
+			r, _ := parseRecurrence("every week")
+			due := startOfDay(time.Now())
+			task := Task{Details: "take out the trash", Due: &due, Recurrence: r}
+			if err := s.CreateTask(&task); err != nil {
+				t.Fatalf("CreateTask() received an error: %v", err)
+			}
+
+			// completing, reopening and completing again leaves a single next
+			// occurrence
+			if _, err := s.MarkTasksAsCompleted(&Task{ID: task.ID}); err != nil {
+				t.Fatalf("MarkTasksAsCompleted() received an error: %v", err)
+			}
+			if err := s.ReopenTasks(&Task{ID: task.ID}); err != nil {
+				t.Fatalf("ReopenTasks() received an error: %v", err)
+			}
+			tasks, err := s.ListTasks(false)
+			if err != nil {
+				t.Fatalf("ListTasks() received an error: %v", err)
+			}
+			if len(tasks) != 1 || tasks[0].ID != task.ID || tasks[0].CompletedAt != nil {
+				t.Errorf("ListTasks(): want only the reopened task %d, got %+v", task.ID, tasks)
+			}
+			if archived, err := s.ListArchivedTasks(); err != nil || len(archived) != 0 {
+				t.Errorf("ListArchivedTasks(): want no tasks, got %+v and %v", archived, err)
+			}
+
+			if _, err := s.MarkTasksAsCompleted(&Task{ID: task.ID}); err != nil {
+				t.Fatalf("MarkTasksAsCompleted() received an error: %v", err)
+			}
+			tasks, err = s.ListTasks(false)
+			if err != nil {
+				t.Fatalf("ListTasks() received an error: %v", err)
+			}
+			if len(tasks) != 1 || !tasks[0].Due.Equal(due.AddDate(0, 0, 7)) {
+				t.Errorf("ListTasks(): want a single next occurrence, got %+v", tasks)
+			}
+
+			// undoing the completion and the reopening brings the first
+			// next occurrence back
+			for i := 0; i < 2; i++ {
+				if _, err := s.Undo(); err != nil {
+					t.Fatalf("Undo() received an error: %v", err)
+				}
+			}
+			tasks, err = s.ListTasks(false)
+			if err != nil {
+				t.Fatalf("ListTasks() received an error: %v", err)
+			}
+			if len(tasks) != 1 || tasks[0].ID == task.ID {
+				t.Errorf("ListTasks(): want the first next occurrence back, got %+v", tasks)
+			}
+
+			if err := s.ReopenTasks(&Task{ID: tasks[0].ID}); err == nil {
+				t.Errorf("ReopenTasks(): expected an error for an incomplete task")
+			}
+		})
+	}
+}
+
+func TestStoreImportTasks(t *testing.T) {
+	for _, backend := range []string{backendBolt, backendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			s, cleanup := newTestStore(t, backend)
+			defer cleanup()
+
+			for _, details := range []string{"a", "b"} {
+				if err := s.CreateTask(&Task{Details: details}); err != nil {
+					t.Fatalf("CreateTask() received an error: %v", err)
+				}
+			}
+
+			tasks := []*Task{{ID: 2, Details: "c"}, {ID: 7, Details: "d"}, {Details: "e"}, {ID: 7, Details: "f"}}
+			if err := s.ImportTasks(tasks...); err != nil {
+				t.Fatalf("ImportTasks() received an error: %v", err)
+			}
+			var ids []int
+			for _, task := range tasks {
+				ids = append(ids, task.ID)
+			}
+			// 2 is taken, and new IDs come after the imported 7
+			if want := []int{8, 7, 9, 10}; fmt.Sprint(ids) != fmt.Sprint(want) {
+				t.Errorf("ImportTasks(): want IDs %v, got %v", want, ids)
+			}
+
+			task := Task{Details: "g"}
+			if err := s.CreateTask(&task); err != nil {
+				t.Fatalf("CreateTask() received an error: %v", err)
+			}
+			if task.ID != 11 {
+				t.Errorf("CreateTask(): want ID 11, got %d", task.ID)
+			}
+
+			if _, err := s.Undo(); err != nil {
+				t.Fatalf("Undo() received an error: %v", err)
+			}
+			if op, err := s.Undo(); err != nil || op.Kind != opImport {
+				t.Fatalf("Undo(): want the import, got %+v and %v", op, err)
+			}
+			if tasks, err := s.ListTasks(false); err != nil || len(tasks) != 2 {
+				t.Errorf("ListTasks(): want the 2 tasks from before the import, got %+v and %v", tasks, err)
+			}
+		})
+	}
+}
+
+func TestDefaultDBPath(t *testing.T) {
+	defer os.Setenv("XDG_DATA_HOME", os.Getenv("XDG_DATA_HOME"))
+
+	os.Setenv("XDG_DATA_HOME", "/data")
+	cases := []struct {
+		backend string
+		want    string
+	}{
+		{backendBolt, "/data/task/tasks.db"},
+		{backendSQLite, "/data/task/tasks.sqlite"},
+	}
+	for _, c := range cases {
+		got, err := defaultDBPath(c.backend)
+		if err != nil {
+			t.Fatalf("defaultDBPath(%q) received an error: %v", c.backend, err)
+		}
+		if got != c.want {
+			t.Errorf("defaultDBPath(%q): want %s, got %s", c.backend, c.want, got)
+		}
+	}
+}
+
+func TestMoveLegacyDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	legacy := filepath.Join(dir, "tasks.db")
+	path := filepath.Join(dir, "data", "task", "tasks.db")
+
+	if err := moveLegacyDB(legacy, path); err == nil {
+		t.Errorf("moveLegacyDB(): expected an error without a legacy store")
+	}
+
+	// some other file is left alone
+	if err := ioutil.WriteFile(legacy, []byte("tasks"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := moveLegacyDB(legacy, path); err == nil {
+		t.Errorf("moveLegacyDB(): expected an error for a file that isn't a task database")
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("moveLegacyDB(): want the file left alone, got %v", err)
+	}
+	os.Remove(legacy)
+
+	s, err := OpenStore(backendBolt, legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateTask(&Task{Details: "buy milk"}); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	if err := moveLegacyDB(legacy, path); err != nil {
+		t.Fatalf("moveLegacyDB() received an error: %v", err)
+	}
+	if s, err = OpenStore(backendBolt, path); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := s.ListTasks(false)
+	s.Close()
+	if err != nil || len(tasks) != 1 || tasks[0].Details != "buy milk" {
+		t.Errorf("moveLegacyDB(): want the legacy tasks at %s, got %+v and %v", path, tasks, err)
+	}
+
+	// an existing store is never replaced
+	if s, err = OpenStore(backendBolt, legacy); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	if err := moveLegacyDB(legacy, path); err == nil {
+		t.Errorf("moveLegacyDB(): expected an error with an existing store")
+	}
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// maxSyncRequestSize caps the size of the body of a sync request
+const maxSyncRequestSize = 32 << 20
+
+// syncRequest pushes the tasks that changed on a machine since it last
+// synced, and asks for the ones that changed on the server since revision
+// Since
+type syncRequest struct {
+	Since int     `json:"since"`
+	Tasks []*Task `json:"tasks"`
+}
+
+// syncResponse has the tasks that changed on the server since the revision
+// asked for, Rev being the latest one
+type syncResponse struct {
+	Rev   int     `json:"rev"`
+	Tasks []*Task `json:"tasks"`
+}
+
+// syncServer keeps the tasks of every machine syncing with it. Each task it
+// saves gets a new revision, so machines can ask for what changed since the
+// last revision they saw without trusting their clocks.
+type syncServer struct {
+	// mu serializes syncs, so revisions are only handed out once
+	mu    sync.Mutex
+	store Store
+	token string
+}
+
+func newSyncServer(store Store, token string) http.Handler {
+	s := &syncServer{store: store, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sync", s.handleSync)
+	return mux
+}
+
+func (s *syncServer) handleSync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.token != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	var req syncRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxSyncRequestSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid sync request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	res, err := s.sync(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (s *syncServer) sync(req *syncRequest) (*syncResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rev, err := s.rev()
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range req.Tasks {
+		task.Rev = rev + 1
+	}
+	saved, err := s.store.MergeTasks(req.Tasks...)
+	if err != nil {
+		return nil, err
+	}
+	if len(saved) > 0 {
+		rev++
+	}
+
+	tasks, err := s.store.ListAllTasks()
+	if err != nil {
+		return nil, err
+	}
+	res := &syncResponse{Rev: rev, Tasks: []*Task{}}
+	for _, task := range tasks {
+		if task.Rev > req.Since {
+			res.Tasks = append(res.Tasks, task)
+		}
+	}
+	return res, nil
+}
+
+// rev returns the latest revision
+func (s *syncServer) rev() (int, error) {
+	tasks, err := s.store.ListAllTasks()
+	if err != nil {
+		return 0, err
+	}
+	rev := 0
+	for _, task := range tasks {
+		if task.Rev > rev {
+			rev = task.Rev
+		}
+	}
+	return rev, nil
+}
+
+// syncTasks pushes the tasks of store that changed since it last synced with
+// the server at serverURL, and merges back the ones that changed there,
+// returning how many tasks were pushed and merged
+func syncTasks(ctx context.Context, client *http.Client, store Store, serverURL, token string) (pushed, merged int, err error) {
+	serverURL = strings.TrimSuffix(serverURL, "/")
+	revKey, pushedKey := "sync "+serverURL+" rev", "sync "+serverURL+" pushed_at"
+
+	req := syncRequest{Tasks: []*Task{}}
+	if v, err := store.Meta(revKey); err != nil {
+		return 0, 0, err
+	} else if v != "" {
+		if req.Since, err = strconv.Atoi(v); err != nil {
+			return 0, 0, fmt.Errorf("invalid sync revision %q: %v", v, err)
+		}
+	}
+	var pushedAt time.Time
+	if v, err := store.Meta(pushedKey); err != nil {
+		return 0, 0, err
+	} else if v != "" {
+		if pushedAt, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			return 0, 0, fmt.Errorf("invalid sync time %q: %v", v, err)
+		}
+	}
+
+	start := time.Now()
+	tasks, err := store.ListAllTasks()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, task := range tasks {
+		if task.UpdatedAt != nil && task.UpdatedAt.After(pushedAt) {
+			req.Tasks = append(req.Tasks, task)
+		}
+	}
+
+	b, err := json.Marshal(&req)
+	if err != nil {
+		return 0, 0, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, serverURL+"/sync", bytes.NewReader(b))
+	if err != nil {
+		return 0, 0, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	httpRes, err := client.Do(httpReq)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer httpRes.Body.Close()
+	if httpRes.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(httpRes.Body, 1024))
+		return 0, 0, fmt.Errorf("sync failed with %s: %s", httpRes.Status, strings.TrimSpace(string(body)))
+	}
+	var res syncResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+		return 0, 0, fmt.Errorf("invalid sync response: %v", err)
+	}
+
+	saved, err := store.MergeTasks(res.Tasks...)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := store.SetMeta(revKey, strconv.Itoa(res.Rev)); err != nil {
+		return 0, 0, err
+	}
+	if err := store.SetMeta(pushedKey, start.Format(time.RFC3339Nano)); err != nil {
+		return 0, 0, err
+	}
+	return len(req.Tasks), len(saved), nil
+}
+
+var syncFlags struct {
+	server string
+	token  string
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&syncFlags.server, "server", os.Getenv("TASK_SYNC_SERVER"), `url of the sync server, as in "http://example.com:8080" (env TASK_SYNC_SERVER)`)
+	syncCmd.Flags().StringVar(&syncFlags.token, "token", os.Getenv("TASK_SYNC_TOKEN"), "token the sync server expects (env TASK_SYNC_TOKEN)")
+	rootCmd.AddCommand(syncCmd)
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync your tasks with a sync server",
+	Long: `Sync your tasks with a server started with "task serve".
+
+Tasks changed on more than one machine keep the last change.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		if syncFlags.server == "" {
+			exitf("Missing sync server, set with --server or TASK_SYNC_SERVER\n")
+		}
+		s, err := currentStore()
+		if err != nil {
+			exitf("%v\n", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		pushed, merged, err := syncTasks(ctx, http.DefaultClient, s, syncFlags.server, syncFlags.token)
+		if err != nil {
+			exitf("%v\n", err)
+		}
+		fmt.Printf("Synced with %s: sent %s and received %s.\n", syncFlags.server, pluralize(pushed, "change"), pluralize(merged, "change"))
+	},
+}
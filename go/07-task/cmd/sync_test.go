@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSync(t *testing.T) {
+	serverStore, cleanup := newTestStore(t, backendBolt)
+	defer cleanup()
+	server := httptest.NewServer(newSyncServer(serverStore, "secret"))
+	defer server.Close()
+
+	a, cleanupA := newTestStore(t, backendBolt)
+	defer cleanupA()
+	b, cleanupB := newTestStore(t, backendSQLite)
+	defer cleanupB()
+
+	sync := func(s Store) {
+		t.Helper()
+		if _, _, err := syncTasks(context.Background(), server.Client(), s, server.URL, "secret"); err != nil {
+			t.Fatalf("syncTasks() received an error: %v", err)
+		}
+	}
+	// contents lists every task of s by details, leaving out IDs, which may
+	// differ between machines
+	contents := func(s Store) string {
+		t.Helper()
+		tasks, err := s.ListAllTasks()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var all []string
+		for _, task := range tasks {
+			all = append(all, fmt.Sprintf("%s:%t:%t", task.Details, task.Completed, task.DeletedAt != nil))
+		}
+		sort.Strings(all)
+		return strings.Join(all, " ")
+	}
+
+	for _, details := range []string{"write report", "buy milk"} {
+		if err := a.CreateTask(&Task{Details: details}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sync(a)
+	sync(b)
+	if got, want := contents(b), "buy milk:false:false write report:false:false"; got != want {
+		t.Fatalf("after the first sync: want %q, got %q", want, got)
+	}
+
+	// both change "write report" and the last change wins, while b deletes
+	// "buy milk" and a adds a task
+	if err := a.UpdateTask(&Task{ID: 1, Details: "write the report"}); err != nil {
+		t.Fatal(err)
+	}
+	report, err := findByDetails(b, "write report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.MarkTasksAsCompleted(&Task{ID: report.ID}); err != nil {
+		t.Fatal(err)
+	}
+	milk, err := findByDetails(b, "buy milk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.DeleteTasks(&Task{ID: milk.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.CreateTask(&Task{Details: "call mom"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sync(a)
+	sync(b)
+	sync(a)
+	want := "buy milk:false:true call mom:false:false write report:true:false"
+	for name, s := range map[string]Store{"a": a, "b": b, "server": serverStore} {
+		if got := contents(s); got != want {
+			t.Errorf("%s: want %q, got %q", name, want, got)
+		}
+	}
+
+	// syncing clears the undo log, so undoing can't revert what was pulled
+	// in and push that to the other machines
+	if op, err := a.Undo(); err != nil || op != nil {
+		t.Errorf("Undo(): want nothing to undo after a sync, got %+v and %v", op, err)
+	}
+	if op, err := b.Undo(); err != nil || op != nil {
+		t.Errorf("Undo(): want nothing to undo after a sync, got %+v and %v", op, err)
+	}
+	sync(b)
+	for name, s := range map[string]Store{"a": a, "b": b, "server": serverStore} {
+		if got := contents(s); got != want {
+			t.Errorf("%s after undoing: want %q, got %q", name, want, got)
+		}
+	}
+
+	// nothing changed since, so nothing is sent
+	pushed, merged, err := syncTasks(context.Background(), server.Client(), a, server.URL, "secret")
+	if err != nil || pushed != 0 || merged != 0 {
+		t.Errorf("syncTasks(): want nothing to sync, got %d, %d and %v", pushed, merged, err)
+	}
+
+	if _, _, err := syncTasks(context.Background(), server.Client(), a, server.URL, "wrong"); err == nil {
+		t.Errorf("syncTasks(): expected an error for a wrong token")
+	}
+	res, err := http.Get(server.URL + "/sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET /sync: want %d, got %d", http.StatusMethodNotAllowed, res.StatusCode)
+	}
+}
+
+func findByDetails(s Store, details string) (*Task, error) {
+	tasks, err := s.ListAllTasks()
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if task.Details == details {
+			return task, nil
+		}
+	}
+	return nil, fmt.Errorf("no task %q", details)
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Task struct {
+	// ID is the number of the task on this machine, and UID identifies it
+	// across machines
+	ID        int        `json:"id"`
+	UID       string     `json:"uid,omitempty"`
+	Details   string     `json:"details"`
+	Completed bool       `json:"completed"`
+	Due       *time.Time `json:"due,omitempty"`
+	Priority  Priority   `json:"priority"`
+	Tags      []string   `json:"tags,omitempty"`
+	Project   string     `json:"project,omitempty"`
+	// Recurrence, if set, spawns the next occurrence of the task when it's
+	// completed
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	// CreatedAt and CompletedAt are unknown for the tasks created or
+	// completed before they were recorded
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// DeletedAt is set for the tasks in the archive
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// NextUID is the UID of the next occurrence spawned by completing a
+	// recurring task, taken back if the task is reopened
+	NextUID string `json:"next_uid,omitempty"`
+	// Purged is set along with DeletedAt for the tasks whose creation was
+	// undone or taken back, which are only kept to tell other machines, and
+	// can't be restored
+	Purged bool `json:"purged,omitempty"`
+	// UpdatedAt is the last time the task changed, the last change winning
+	// when syncing
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// Rev is the revision a sync server saved the task at
+	Rev int `json:"rev,omitempty"`
+}
+
+// Overdue reports whether the task is still incomplete past its due date
+func (t *Task) Overdue(now time.Time) bool {
+	return !t.Completed && t.Due != nil && t.Due.Before(startOfDay(now))
+}
+
+// HasTag reports whether the task is tagged with tag
+func (t *Task) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	for _, tt := range t.Tags {
+		if tt == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Meta returns the details of the task other than its description, as in
+// "due: 2020-05-01, priority: high, project: q3, tags: work"
+func (t *Task) Meta() string {
+	var meta []string
+	if t.Due != nil {
+		meta = append(meta, "due: "+t.Due.Format(dateLayout))
+	}
+	if t.Recurrence != nil {
+		meta = append(meta, "repeats: "+t.Recurrence.String())
+	}
+	if t.Priority != PriorityNone {
+		meta = append(meta, "priority: "+t.Priority.String())
+	}
+	if t.Project != "" {
+		meta = append(meta, "project: "+t.Project)
+	}
+	if len(t.Tags) > 0 {
+		meta = append(meta, "tags: "+strings.Join(t.Tags, " "))
+	}
+	if t.CompletedAt != nil {
+		meta = append(meta, "completed: "+t.CompletedAt.Format("2006-01-02 15:04"))
+	}
+	return strings.Join(meta, ", ")
+}
+
+// normalizeTags lowercases tags, drops empty and duplicate ones, and sorts
+// them
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	var normalized []string
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "+"))
+}
+
+// Priority is how important a task is, PriorityNone being the lowest
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+var priorityNames = []string{"none", "low", "medium", "high"}
+
+func (p Priority) String() string {
+	if p < PriorityNone || p > PriorityHigh {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// ParsePriority parses a priority by its name or first letter, as in "high"
+// or "h"
+func ParsePriority(s string) (Priority, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "":
+		return PriorityNone, nil
+	case "l", "m", "h", "n":
+		for i, name := range priorityNames {
+			if name[0] == s[0] {
+				return Priority(i), nil
+			}
+		}
+	}
+	for i, name := range priorityNames {
+		if name == s {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("unknown priority %q, expected one of %s", s, strings.Join(priorityNames, ", "))
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+func (p *Priority) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := ParsePriority(s)
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// nextOccurrence returns the task to do after t, if it repeats
+func (t *Task) nextOccurrence(now time.Time) *Task {
+	if t.Recurrence == nil {
+		return nil
+	}
+	from := now
+	if t.Due != nil {
+		from = *t.Due
+	}
+	due := t.Recurrence.NextAfter(from, now)
+	return &Task{
+		Details:    t.Details,
+		Due:        &due,
+		Priority:   t.Priority,
+		Tags:       t.Tags,
+		Project:    t.Project,
+		Recurrence: t.Recurrence,
+	}
+}
+
+// touch records that t changed at now, giving it a UID if it has none yet
+func (t *Task) touch(now time.Time) {
+	if t.UID == "" {
+		t.UID = newUID()
+	}
+	t.UpdatedAt = &now
+}
+
+// newer reports whether t wins over other when syncing, which is when it
+// changed last, or has the greater UID on a tie so every machine agrees
+func (t *Task) newer(other *Task) bool {
+	switch {
+	case t.UpdatedAt == nil:
+		return false
+	case other.UpdatedAt == nil || t.UpdatedAt.After(*other.UpdatedAt):
+		return true
+	case t.UpdatedAt.Equal(*other.UpdatedAt):
+		return t.syncKey() > other.syncKey()
+	}
+	return false
+}
+
+// syncKey is t without what differs between machines, to compare it by
+func (t *Task) syncKey() string {
+	c := *t
+	c.ID, c.Rev = 0, 0
+	b, _ := json.Marshal(&c)
+	return string(b)
+}
+
+func newUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
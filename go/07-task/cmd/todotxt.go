@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// todo.txt has no place for IDs and due dates, so they're kept in the usual
+// key:value extensions, along with the rec: of the recurrence add-on and the
+// pri: completed tasks keep their priority in
+var (
+	todoTxtDateRegexp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	todoTxtPriRegexp  = regexp.MustCompile(`^\(([A-Z])\)$`)
+	todoTxtRecRegexp  = regexp.MustCompile(`^\+?(\d*)([dwmyb])$`)
+)
+
+// todoTxtPriorities maps priorities to the letters of todo.txt, where A is
+// the highest
+var todoTxtPriorities = map[Priority]string{
+	PriorityHigh:   "A",
+	PriorityMedium: "B",
+	PriorityLow:    "C",
+}
+
+// formatTodoTxtTask formats t as a line of todo.txt, as in
+// "(A) 2020-05-01 write report +q3 @work due:2020-05-08 id:3"
+func formatTodoTxtTask(t *Task) string {
+	var parts []string
+	pri := todoTxtPriorities[t.Priority]
+	if t.Completed {
+		parts = append(parts, "x")
+		// the creation date can only follow the completion date
+		if t.CompletedAt != nil {
+			parts = append(parts, t.CompletedAt.Format(dateLayout))
+			if t.CreatedAt != nil {
+				parts = append(parts, t.CreatedAt.Format(dateLayout))
+			}
+		}
+	} else {
+		if pri != "" {
+			parts = append(parts, "("+pri+")")
+		}
+		if t.CreatedAt != nil {
+			parts = append(parts, t.CreatedAt.Format(dateLayout))
+		}
+	}
+
+	parts = append(parts, t.Details)
+	if t.Project != "" {
+		parts = append(parts, "+"+strings.Join(strings.Fields(t.Project), "_"))
+	}
+	for _, tag := range t.Tags {
+		parts = append(parts, "@"+tag)
+	}
+	if t.Due != nil {
+		parts = append(parts, "due:"+t.Due.Format(dateLayout))
+	}
+	if rec, ok := todoTxtRec(t.Recurrence); ok {
+		parts = append(parts, "rec:"+rec)
+	}
+	if t.Completed && pri != "" {
+		parts = append(parts, "pri:"+pri)
+	}
+	if t.ID > 0 {
+		parts = append(parts, "id:"+strconv.Itoa(t.ID))
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseTodoTxtTask parses a line of todo.txt. The first +project is the
+// task's project, and @contexts and any other +projects are its tags.
+func parseTodoTxtTask(line string) (*Task, error) {
+	t := &Task{}
+	words := strings.Fields(line)
+
+	if len(words) > 0 && words[0] == "x" {
+		t.Completed = true
+		words = words[1:]
+		if len(words) > 0 && todoTxtDateRegexp.MatchString(words[0]) {
+			d, err := parseTime(words[0], dateLayout)
+			if err != nil {
+				return nil, err
+			}
+			t.CompletedAt = d
+			words = words[1:]
+		}
+	} else if len(words) > 0 {
+		if m := todoTxtPriRegexp.FindStringSubmatch(words[0]); m != nil {
+			t.Priority = todoTxtPriority(m[1])
+			words = words[1:]
+		}
+	}
+	if len(words) > 0 && todoTxtDateRegexp.MatchString(words[0]) {
+		d, err := parseTime(words[0], dateLayout)
+		if err != nil {
+			return nil, err
+		}
+		t.CreatedAt = d
+		words = words[1:]
+	}
+
+	var details, tags []string
+	for _, w := range words {
+		switch {
+		case len(w) > 1 && w[0] == '+':
+			if t.Project == "" {
+				t.Project = w[1:]
+			} else {
+				tags = append(tags, w[1:])
+			}
+			continue
+		case len(w) > 1 && w[0] == '@':
+			tags = append(tags, w[1:])
+			continue
+		}
+
+		if i := strings.Index(w, ":"); i > 0 && i < len(w)-1 {
+			key, value := w[:i], w[i+1:]
+			switch key {
+			case "due":
+				d, err := parseTime(value, dateLayout)
+				if err != nil {
+					return nil, fmt.Errorf("invalid due date %q", value)
+				}
+				t.Due = d
+				continue
+			case "id":
+				id, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid id %q", value)
+				}
+				t.ID = id
+				continue
+			case "pri":
+				t.Priority = todoTxtPriority(value)
+				continue
+			case "rec":
+				r, err := parseTodoTxtRec(value)
+				if err != nil {
+					return nil, err
+				}
+				t.Recurrence = r
+				continue
+			}
+		}
+		details = append(details, w)
+	}
+
+	t.Details = strings.Join(details, " ")
+	t.Tags = normalizeTags(tags)
+	if t.Recurrence != nil && t.Recurrence.Freq == freqMonthly && t.Due != nil {
+		t.Recurrence.MonthDay = t.Due.Day()
+	}
+	if t.Details == "" {
+		return nil, fmt.Errorf("missing task details in %q", line)
+	}
+	return t, nil
+}
+
+func todoTxtPriority(letter string) Priority {
+	for p, l := range todoTxtPriorities {
+		if l == letter {
+			return p
+		}
+	}
+	if len(letter) == 1 && letter[0] > 'C' && letter[0] <= 'Z' {
+		return PriorityLow
+	}
+	return PriorityNone
+}
+
+// todoTxtRec returns the rec: value of r, as in "1w" or "3d", if it has one.
+// Weekly recurrences on days other than every weekday have none.
+func todoTxtRec(r *Recurrence) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	n := r.Interval
+	if n < 1 {
+		n = 1
+	}
+	switch {
+	case r.Freq == freqDaily:
+		return fmt.Sprintf("%dd", n), true
+	case r.Freq == freqWeekly && len(r.Weekdays) == 0:
+		return fmt.Sprintf("%dw", n), true
+	case r.Freq == freqWeekly && r.String() == "weekly on mon,tue,wed,thu,fri":
+		return "1b", true
+	case r.Freq == freqMonthly:
+		return fmt.Sprintf("%dm", n), true
+	}
+	return "", false
+}
+
+func parseTodoTxtRec(s string) (*Recurrence, error) {
+	m := todoTxtRecRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("invalid recurrence rec:%s", s)
+	}
+	n := 1
+	if m[1] != "" {
+		n, _ = strconv.Atoi(m[1])
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("invalid recurrence rec:%s", s)
+	}
+	switch m[2] {
+	case "d":
+		return &Recurrence{Freq: freqDaily, Interval: n}, nil
+	case "w":
+		return &Recurrence{Freq: freqWeekly, Interval: n}, nil
+	case "m":
+		return &Recurrence{Freq: freqMonthly, Interval: n}, nil
+	case "y":
+		return &Recurrence{Freq: freqMonthly, Interval: 12 * n}, nil
+	default:
+		if n != 1 {
+			return nil, fmt.Errorf("unsupported recurrence rec:%s, only every business day is", s)
+		}
+		return parseRecurrence("weekdays")
+	}
+}
@@ -0,0 +1,468 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+}
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Manage your tasks in a full-screen interface",
+	Long: `Manage your tasks in a full-screen interface.
+
+  up/down, j/k   move between tasks          pgup/pgdn, g/G  move by page, to the ends
+  space, x       toggle completion           a               add a task
+  enter, e       edit the task               d               delete the task
+  /              filter tasks as you type    c               show completed tasks too
+  u              undo the last change        q, esc          quit`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		screen, err := tcell.NewScreen()
+		if err != nil {
+			exitf("%v\n", err)
+		}
+		if err := screen.Init(); err != nil {
+			exitf("%v\n", err)
+		}
+		err = runUI(screen)
+		screen.Fini()
+		if err != nil {
+			exitf("%v\n", err)
+		}
+	},
+}
+
+// The list of modes the interface can be in, the ones other than uiNormal
+// reading a line of input
+const (
+	uiNormal = iota
+	uiFilter
+	uiEdit
+	uiAdd
+)
+
+// ui is the state of the interface
+type ui struct {
+	screen tcell.Screen
+
+	// tasks are the tasks matching filter, and cursor is the index of the
+	// selected one, which offset keeps on the screen
+	tasks          []*Task
+	filter         string
+	showCompleted  bool
+	cursor, offset int
+
+	mode int
+	// input is the line being typed, and pos is the cursor in it
+	input  []rune
+	pos    int
+	status string
+}
+
+// runUI runs the interface on screen until it's quit
+func runUI(screen tcell.Screen) error {
+	u := &ui{screen: screen}
+	if err := u.reload(); err != nil {
+		return err
+	}
+	for {
+		u.draw()
+		switch ev := screen.PollEvent().(type) {
+		case nil:
+			// the screen was finalized
+			return nil
+		case *tcell.EventResize:
+			screen.Sync()
+		case *tcell.EventKey:
+			quit, err := u.handleKey(ev)
+			if err != nil {
+				u.status = err.Error()
+			}
+			if quit {
+				return nil
+			}
+		}
+	}
+}
+
+// reload lists the tasks again, keeping the selected one selected
+func (u *ui) reload() error {
+	selected := 0
+	if t := u.selected(); t != nil {
+		selected = t.ID
+	}
+
+	tasks, err := ListTasks(false)
+	if err != nil {
+		return err
+	}
+	if u.showCompleted {
+		completed, err := ListTasks(true)
+		if err != nil {
+			return err
+		}
+		tasks = append(tasks, completed...)
+	}
+
+	u.tasks = u.tasks[:0]
+	for _, t := range tasks {
+		if matchesFilter(t, u.filter) {
+			u.tasks = append(u.tasks, t)
+		}
+	}
+	if err := sortTasks(u.tasks, "id"); err != nil {
+		return err
+	}
+
+	for i, t := range u.tasks {
+		if t.ID == selected {
+			u.cursor = i
+		}
+	}
+	u.move(0)
+	return nil
+}
+
+// matchesFilter reports whether the details, project or any of the tags of t
+// contain filter, ignoring case
+func matchesFilter(t *Task, filter string) bool {
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	if filter == "" {
+		return true
+	}
+	if strings.Contains(strings.ToLower(t.Details), filter) || strings.Contains(strings.ToLower(t.Project), filter) {
+		return true
+	}
+	for _, tag := range t.Tags {
+		if strings.Contains(tag, strings.TrimPrefix(filter, "+")) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *ui) selected() *Task {
+	if u.cursor < 0 || u.cursor >= len(u.tasks) {
+		return nil
+	}
+	return u.tasks[u.cursor]
+}
+
+// move moves the cursor by delta tasks, staying within the list
+func (u *ui) move(delta int) {
+	u.cursor += delta
+	if u.cursor >= len(u.tasks) {
+		u.cursor = len(u.tasks) - 1
+	}
+	if u.cursor < 0 {
+		u.cursor = 0
+	}
+}
+
+// listHeight is the number of tasks that fit between the header and footer
+func (u *ui) listHeight() int {
+	_, h := u.screen.Size()
+	if h < 3 {
+		return 1
+	}
+	return h - 2
+}
+
+func (u *ui) handleKey(ev *tcell.EventKey) (quit bool, err error) {
+	if ev.Key() == tcell.KeyCtrlC {
+		return true, nil
+	}
+	if u.mode != uiNormal {
+		return false, u.handleInputKey(ev)
+	}
+
+	u.status = ""
+	switch ev.Key() {
+	case tcell.KeyUp:
+		u.move(-1)
+	case tcell.KeyDown:
+		u.move(1)
+	case tcell.KeyPgUp:
+		u.move(-u.listHeight())
+	case tcell.KeyPgDn:
+		u.move(u.listHeight())
+	case tcell.KeyHome:
+		u.move(-len(u.tasks))
+	case tcell.KeyEnd:
+		u.move(len(u.tasks))
+	case tcell.KeyEscape:
+		return true, nil
+	case tcell.KeyEnter:
+		u.startEdit()
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			return true, nil
+		case 'k':
+			u.move(-1)
+		case 'j':
+			u.move(1)
+		case 'g':
+			u.move(-len(u.tasks))
+		case 'G':
+			u.move(len(u.tasks))
+		case ' ', 'x':
+			return false, u.toggle()
+		case 'e':
+			u.startEdit()
+		case 'a':
+			u.startInput(uiAdd, "")
+		case 'd':
+			return false, u.delete()
+		case '/':
+			u.startInput(uiFilter, u.filter)
+		case 'c':
+			u.showCompleted = !u.showCompleted
+			return false, u.reload()
+		case 'u':
+			return false, u.undo()
+		}
+	}
+	return false, nil
+}
+
+func (u *ui) handleInputKey(ev *tcell.EventKey) error {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		if u.mode == uiFilter {
+			u.filter = ""
+		}
+		u.mode = uiNormal
+		return u.reload()
+	case tcell.KeyEnter:
+		mode := u.mode
+		u.mode = uiNormal
+		return u.submit(mode, strings.TrimSpace(string(u.input)))
+	case tcell.KeyLeft:
+		if u.pos > 0 {
+			u.pos--
+		}
+	case tcell.KeyRight:
+		if u.pos < len(u.input) {
+			u.pos++
+		}
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		u.pos = 0
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		u.pos = len(u.input)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if u.pos > 0 {
+			u.input = append(u.input[:u.pos-1], u.input[u.pos:]...)
+			u.pos--
+		}
+	case tcell.KeyDelete:
+		if u.pos < len(u.input) {
+			u.input = append(u.input[:u.pos], u.input[u.pos+1:]...)
+		}
+	case tcell.KeyCtrlU:
+		u.input = u.input[:0]
+		u.pos = 0
+	case tcell.KeyRune:
+		u.input = append(u.input[:u.pos], append([]rune{ev.Rune()}, u.input[u.pos:]...)...)
+		u.pos++
+	default:
+		return nil
+	}
+
+	// the list follows the filter as it's typed
+	if u.mode == uiFilter {
+		u.filter = string(u.input)
+		return u.reload()
+	}
+	return nil
+}
+
+func (u *ui) startInput(mode int, value string) {
+	u.mode = mode
+	u.input = []rune(value)
+	u.pos = len(u.input)
+	u.status = ""
+}
+
+func (u *ui) startEdit() {
+	if t := u.selected(); t != nil {
+		u.startInput(uiEdit, t.Details)
+	}
+}
+
+func (u *ui) submit(mode int, value string) error {
+	switch mode {
+	case uiFilter:
+		u.filter = value
+	case uiAdd:
+		if value == "" {
+			return nil
+		}
+		task := Task{Details: value}
+		if err := CreateTask(&task); err != nil {
+			return err
+		}
+		u.status = fmt.Sprintf("Added %q to your task list.", task.Details)
+		if err := u.reload(); err != nil {
+			return err
+		}
+		u.selectID(task.ID)
+		return nil
+	case uiEdit:
+		t := u.selected()
+		if t == nil || value == "" || value == t.Details {
+			return nil
+		}
+		old := t.Details
+		t.Details = value
+		if err := UpdateTask(t); err != nil {
+			return err
+		}
+		u.status = fmt.Sprintf("You have changed %q to %q.", old, t.Details)
+	}
+	return u.reload()
+}
+
+func (u *ui) selectID(id int) {
+	for i, t := range u.tasks {
+		if t.ID == id {
+			u.cursor = i
+		}
+	}
+}
+
+func (u *ui) toggle() error {
+	t := u.selected()
+	if t == nil {
+		return nil
+	}
+	if t.Completed {
+		if err := ReopenTask(t); err != nil {
+			return err
+		}
+		u.status = fmt.Sprintf("You have reopened the %q task.", t.Details)
+	} else {
+		if err := MarkTaskAsCompleted(t); err != nil {
+			return err
+		}
+		u.status = fmt.Sprintf("You have completed the %q task.", t.Details)
+	}
+	return u.reload()
+}
+
+func (u *ui) delete() error {
+	t := u.selected()
+	if t == nil {
+		return nil
+	}
+	if err := DeleteTask(t); err != nil {
+		return err
+	}
+	u.status = fmt.Sprintf("You have deleted the %q task.", t.Details)
+	return u.reload()
+}
+
+func (u *ui) undo() error {
+	op, err := Undo()
+	if err != nil {
+		return err
+	}
+	if op == nil {
+		u.status = "There is nothing to undo."
+		return nil
+	}
+	u.status = fmt.Sprintf("You have undone %q.", op.Kind)
+	return u.reload()
+}
+
+func (u *ui) draw() {
+	s := u.screen
+	s.Clear()
+	w, h := s.Size()
+	now := time.Now()
+
+	bold := tcell.StyleDefault.Bold(true)
+	header := fmt.Sprintf(" task: %s", pluralize(len(u.tasks), "task"))
+	if u.showCompleted {
+		header += ", completed included"
+	}
+	if u.filter != "" {
+		header += fmt.Sprintf(", matching %q", u.filter)
+	}
+	drawText(s, 0, 0, w, bold.Reverse(true), padRight(header, w))
+
+	// keep the cursor on the screen
+	height := u.listHeight()
+	if u.cursor < u.offset {
+		u.offset = u.cursor
+	}
+	if u.cursor >= u.offset+height {
+		u.offset = u.cursor - height + 1
+	}
+
+	if len(u.tasks) == 0 {
+		drawText(s, 1, 1, w-1, tcell.StyleDefault.Dim(true), "No tasks. Press a to add one.")
+	}
+	for i := u.offset; i < len(u.tasks) && i < u.offset+height; i++ {
+		t := u.tasks[i]
+		check := "[ ]"
+		if t.Completed {
+			check = "[x]"
+		}
+		line := fmt.Sprintf(" %s %d. %s", check, t.ID, t.Details)
+		if meta := t.Meta(); meta != "" {
+			line += " (" + meta + ")"
+		}
+
+		style := tcell.StyleDefault
+		switch {
+		case t.Completed:
+			style = style.Dim(true)
+		case t.Overdue(now):
+			style = style.Foreground(tcell.ColorRed)
+		}
+		if i == u.cursor {
+			style = style.Reverse(true)
+			line = padRight(line, w)
+		}
+		drawText(s, 0, 1+i-u.offset, w, style, line)
+	}
+
+	footer := u.status
+	if footer == "" {
+		footer = " space: toggle  a: add  e: edit  d: delete  /: filter  c: completed  u: undo  q: quit"
+	}
+	s.HideCursor()
+	if u.mode != uiNormal {
+		prompt := map[int]string{uiFilter: "Filter: ", uiEdit: "Edit: ", uiAdd: "Add: "}[u.mode]
+		footer = prompt + string(u.input)
+		s.ShowCursor(len([]rune(prompt))+u.pos, h-1)
+	}
+	drawText(s, 0, h-1, w, tcell.StyleDefault, footer)
+	s.Show()
+}
+
+// drawText draws text on a row from x, cutting it off at width
+func drawText(s tcell.Screen, x, y, width int, style tcell.Style, text string) {
+	for _, r := range text {
+		if x >= width {
+			return
+		}
+		s.SetContent(x, y, r, nil, style)
+		x++
+	}
+}
+
+func padRight(s string, width int) string {
+	if n := width - len([]rune(s)); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+// runTestUI runs the interface on a simulated screen with keys, quitting
+// after them, and returns what's left on the screen
+func runTestUI(t *testing.T, keys ...*tcell.EventKey) string {
+	t.Helper()
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(80, 10)
+
+	go func() {
+		for _, key := range keys {
+			screen.PostEventWait(key)
+		}
+		screen.PostEventWait(tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModNone))
+	}()
+
+	var contents []rune
+	if err := runUI(screen); err != nil {
+		t.Fatalf("runUI() received an error: %v", err)
+	}
+	cells, w, _ := screen.GetContents()
+	for i, c := range cells {
+		if i > 0 && i%w == 0 {
+			contents = append(contents, '\n')
+		}
+		contents = append(contents, c.Runes...)
+	}
+	return string(contents)
+}
+
+func typeKeys(s string) []*tcell.EventKey {
+	var keys []*tcell.EventKey
+	for _, r := range s {
+		keys = append(keys, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	return keys
+}
+
+func key(k tcell.Key) *tcell.EventKey {
+	return tcell.NewEventKey(k, 0, tcell.ModNone)
+}
+
+func TestUI(t *testing.T) {
+	s, cleanup := newTestStore(t, backendBolt)
+	defer cleanup()
+	defaultStore = s
+	defer func() { defaultStore = nil }()
+
+	for _, details := range []string{"write report", "buy milk", "call mom"} {
+		if err := CreateTask(&Task{Details: details}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var keys []*tcell.EventKey
+	// complete "buy milk"
+	keys = append(keys, key(tcell.KeyDown))
+	keys = append(keys, typeKeys(" ")...)
+	// edit "call mom", which is now the second task
+	keys = append(keys, typeKeys("e")...)
+	keys = append(keys, key(tcell.KeyCtrlU))
+	keys = append(keys, typeKeys("call dad")...)
+	keys = append(keys, key(tcell.KeyEnter))
+	// add a task
+	keys = append(keys, typeKeys("awalk the dog")...)
+	keys = append(keys, key(tcell.KeyEnter))
+	// filter down to "write report" and delete it
+	keys = append(keys, typeKeys("/REP")...)
+	keys = append(keys, key(tcell.KeyEnter))
+	keys = append(keys, typeKeys("d")...)
+	// clear the filter
+	keys = append(keys, typeKeys("/")...)
+	keys = append(keys, key(tcell.KeyEscape))
+	screen := runTestUI(t, keys...)
+
+	tasks, err := ListTasks(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var details []string
+	for _, task := range tasks {
+		details = append(details, task.Details)
+	}
+	if got, want := strings.Join(details, ", "), "call dad, walk the dog"; got != want {
+		t.Errorf("incomplete tasks: want %q, got %q", want, got)
+	}
+	if completed, err := ListTasks(true); err != nil || len(completed) != 1 || completed[0].Details != "buy milk" {
+		t.Errorf("completed tasks: want \"buy milk\", got %+v and %v", completed, err)
+	}
+	for _, want := range []string{"[ ] 3. call dad", "[ ] 4. walk the dog"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("screen: expected %q in\n%s", want, screen)
+		}
+	}
+
+	// show completed tasks, reopen "buy milk" and undo it
+	// the selection stays on "call dad"
+	screen = runTestUI(t, typeKeys("ck ")...)
+	if !strings.Contains(screen, "[ ] 2. buy milk") || !strings.Contains(screen, "reopened the \"buy milk\" task") {
+		t.Errorf("screen: expected \"buy milk\" to be reopened in\n%s", screen)
+	}
+	screen = runTestUI(t, typeKeys("cu")...)
+	if !strings.Contains(screen, "[x] 2. buy milk") {
+		t.Errorf("screen: expected \"buy milk\" to be completed again in\n%s", screen)
+	}
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last change to your tasks",
+	Run: func(cmd *cobra.Command, _ []string) {
+		op, err := Undo()
+		if err != nil {
+			exitf("%v\n", err)
+		}
+		if op == nil {
+			fmt.Println("There is nothing to undo.")
+			os.Exit(0)
+		}
+
+		var ids []string
+		for _, c := range op.Changes {
+			ids = append(ids, fmt.Sprint(c.ID))
+		}
+		noun := "task"
+		if len(ids) > 1 {
+			noun = "tasks"
+		}
+		fmt.Printf("You have undone %q on %s %s.\n", op.Kind, noun, strings.Join(ids, ", "))
+	},
+}
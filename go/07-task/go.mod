@@ -3,6 +3,9 @@ module github.com/ramin0/live/go/task
 go 1.14
 
 require (
-	github.com/boltdb/bolt v1.3.1
+	github.com/gdamore/tcell v1.4.0
+	github.com/mattn/go-sqlite3 v2.0.3+incompatible
 	github.com/spf13/cobra v1.0.0
+	go.etcd.io/bbolt v1.3.6
+	golang.org/x/sys v0.10.0 // indirect
 )
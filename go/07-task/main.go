@@ -12,8 +12,24 @@ Usage:
 
 Available Commands:
   add         Add a new task to your TODO list
-  do          Mark a task on your TODO list as complete
+  archive     List your deleted tasks
+  completed   List your completed tasks
+  do          Mark tasks on your TODO list as complete
+  edit        Change the details of a task
+  export      Export your tasks to JSON, CSV or todo.txt
+  import      Import tasks from JSON, CSV or todo.txt
   list        List all of your incomplete tasks
+  restore     Restore deleted tasks from the archive
+  rm          Delete tasks from your TODO list
+  serve       Run a sync server for your machines to sync tasks with
+  stats       Show your completion streaks and throughput
+  sync        Sync your tasks with a sync server
+  ui          Manage your tasks in a full-screen interface
+  undo        Undo the last change to your tasks
+
+Flags:
+      --backend string   storage backend, bolt or sqlite (env TASK_BACKEND) (default "bolt")
+      --db string        path of the task database, defaults to $XDG_DATA_HOME/task/tasks.db (env TASK_DB)
 
 Use "task [command] --help" for more information about a command.
 */
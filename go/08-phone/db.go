@@ -0,0 +1,94 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// database is a connection to either a SQLite or a Postgres database, which
+// only differ in how they write query placeholders
+type database struct {
+	*sql.DB
+	driver string
+}
+
+// The list of supported database drivers
+const (
+	driverSQLite   = "sqlite3"
+	driverPostgres = "postgres"
+)
+
+// openDatabase opens the database at dsn with driver, or with the one
+// detectDriver picks if driver is empty
+func openDatabase(driver, dsn string) (*database, error) {
+	if driver == "" {
+		driver = detectDriver(dsn)
+	}
+	if driver != driverSQLite && driver != driverPostgres {
+		return nil, fmt.Errorf("unknown driver %q", driver)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &database{DB: db, driver: driver}, nil
+}
+
+// detectDriver guesses the driver of dsn, which is Postgres for a
+// postgres:// url or a set of key=value settings as in
+// "host=localhost dbname=app", and SQLite for anything else, taken as the
+// path of a file
+func detectDriver(dsn string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return driverPostgres
+	}
+	settings := strings.Fields(dsn)
+	if len(settings) == 0 {
+		return driverSQLite
+	}
+	for _, s := range settings {
+		i := strings.Index(s, "=")
+		if i < 1 || strings.IndexFunc(s[:i], func(r rune) bool {
+			return (r < 'a' || r > 'z') && r != '_'
+		}) != -1 {
+			return driverSQLite
+		}
+	}
+	return driverPostgres
+}
+
+// placeholder returns the placeholder of the i-th parameter of a query,
+// counting from 1
+func (db *database) placeholder(i int) string {
+	if db.driver == driverPostgres {
+		return fmt.Sprintf("$%d", i)
+	}
+	return "?"
+}
+
+// defaultKey returns the system column identifying the rows of any table,
+// used when a table's own key isn't given
+func (db *database) defaultKey() string {
+	if db.driver == driverPostgres {
+		return "ctid"
+	}
+	return "rowid"
+}
+
+// quoteIdent quotes a possibly schema-qualified identifier, as in
+// "public"."users", so it's safe to use in a query
+func quoteIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = `"` + strings.Replace(p, `"`, `""`, -1) + `"`
+	}
+	return strings.Join(parts, ".")
+}
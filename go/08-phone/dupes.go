@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ramin0/live/go/phone/phone"
+)
+
+// The list of formats merge reports can be written in
+const (
+	reportJSON = "json"
+	reportCSV  = "csv"
+)
+
+// fuzzyNumber is a phone number read as leniently as possible, so that ways
+// of writing it Parse doesn't understand can still be matched
+type fuzzyNumber struct {
+	row tableRow
+	// number is nil when nothing but the digits could be read
+	number *phone.Number
+	// missingPlus is whether the number only parsed as an international
+	// number, as in "447911123456"
+	missingPlus bool
+	// national is the national significant number, or the digits without
+	// their leading zeros if there's no number
+	national string
+}
+
+func readFuzzy(r tableRow, region string) (*fuzzyNumber, bool) {
+	if !r.Value.Valid {
+		return nil, false
+	}
+	if n, err := phone.Parse(r.Value.String, region); err == nil {
+		return &fuzzyNumber{row: r, number: n, national: n.NationalNumber}, true
+	}
+	digits := strings.Map(func(c rune) rune {
+		if '0' <= c && c <= '9' {
+			return c
+		}
+		return -1
+	}, r.Value.String)
+	if n, err := phone.Parse("+"+digits, ""); err == nil {
+		return &fuzzyNumber{row: r, number: n, missingPlus: true, national: n.NationalNumber}, true
+	}
+	// the trunk prefix is made of zeros in most regions
+	if digits = strings.TrimLeft(digits, "0"); len(digits) < 6 {
+		return nil, false
+	}
+	return &fuzzyNumber{row: r, national: digits}, true
+}
+
+// matchFuzzy returns how confident it is that a and b are the same number,
+// and why, or false if they're not
+func matchFuzzy(a, b *fuzzyNumber) (float64, string, bool) {
+	if a.national != b.national {
+		return 0, "", false
+	}
+	if a.number == nil || b.number == nil {
+		return 0.6, "same digits, without a country code or trunk prefix", true
+	}
+	if a.number.CountryCode != b.number.CountryCode {
+		return 0.5, "same national number, with a different country code", true
+	}
+	switch {
+	case a.number.Extension != b.number.Extension && a.number.Extension != "" && b.number.Extension != "":
+		return 0, "", false
+	case a.number.Extension != b.number.Extension:
+		return 0.7, "same number, with an extension on one only", true
+	case a.missingPlus || b.missingPlus:
+		return 0.9, "same number, missing the + before its country code", true
+	}
+	return 1, "same number", true
+}
+
+type dupeRow struct {
+	Key        interface{} `json:"key"`
+	Value      string      `json:"value"`
+	Normalized string      `json:"normalized,omitempty"`
+	Confidence float64     `json:"confidence,omitempty"`
+	Reason     string      `json:"reason,omitempty"`
+}
+
+// dupeGroup is a row to keep, along with its candidate duplicates
+type dupeGroup struct {
+	Winner     dupeRow   `json:"winner"`
+	Duplicates []dupeRow `json:"duplicates"`
+}
+
+func newDupeRow(n *fuzzyNumber) dupeRow {
+	r := dupeRow{Key: n.row.Key, Value: n.row.Value.String}
+	if n.number != nil {
+		r.Normalized = e164(n.number)
+	}
+	return r
+}
+
+// findDuplicates groups rows by national number, the first row of a group
+// being the one to keep, and lists the rows that may duplicate it. Rows with
+// a different extension than the kept one aren't duplicates of it, and are
+// grouped again among themselves, the same way.
+func findDuplicates(rows []tableRow, region string) []dupeGroup {
+	var groups [][]*fuzzyNumber
+	index := map[string]int{}
+	for _, r := range rows {
+		n, ok := readFuzzy(r, region)
+		if !ok {
+			continue
+		}
+		i, ok := index[n.national]
+		if !ok {
+			i = len(groups)
+			index[n.national] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], n)
+	}
+
+	var result []dupeGroup
+	for _, g := range groups {
+		for len(g) > 0 {
+			group := dupeGroup{Winner: newDupeRow(g[0])}
+			var rest []*fuzzyNumber
+			for _, n := range g[1:] {
+				confidence, reason, ok := matchFuzzy(g[0], n)
+				if !ok {
+					rest = append(rest, n)
+					continue
+				}
+				d := newDupeRow(n)
+				d.Confidence, d.Reason = confidence, reason
+				group.Duplicates = append(group.Duplicates, d)
+			}
+			if len(group.Duplicates) > 0 {
+				result = append(result, group)
+			}
+			g = rest
+		}
+	}
+	return result
+}
+
+// writeMergeReport writes groups as a JSON array, or as CSV rows with the
+// number of the group they're in
+func writeMergeReport(w io.Writer, groups []dupeGroup, format string) error {
+	switch format {
+	case reportJSON:
+		if groups == nil {
+			groups = []dupeGroup{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(groups)
+	case reportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"group", "role", "key", "value", "normalized", "confidence", "reason"}); err != nil {
+			return err
+		}
+		for i, g := range groups {
+			group := fmt.Sprint(i + 1)
+			if err := cw.Write([]string{group, "keep", fmt.Sprint(g.Winner.Key), g.Winner.Value, g.Winner.Normalized, "", ""}); err != nil {
+				return err
+			}
+			for _, d := range g.Duplicates {
+				if err := cw.Write([]string{
+					group, "duplicate", fmt.Sprint(d.Key), d.Value, d.Normalized,
+					fmt.Sprintf("%.2f", d.Confidence), d.Reason,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// tableDuplicates finds the candidate duplicates among the phone numbers of
+// a table, without changing anything
+func tableDuplicates(db *database, opts normalizeOptions) ([]dupeGroup, error) {
+	if _, ok := phone.LookupRegion(opts.Region); !ok {
+		return nil, fmt.Errorf("unknown region %q", opts.Region)
+	}
+	query, err := rowsQuery(db, &opts)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := loadRows(db, query)
+	if err != nil {
+		return nil, err
+	}
+	return findDuplicates(rows, opts.Region), nil
+}
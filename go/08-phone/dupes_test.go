@@ -0,0 +1,94 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	var rows []tableRow
+	for i, v := range []string{
+		"(202) 555-0123",
+		"+1 202 555 0123",
+		"202-555-0123 x12",
+		"12025550123",
+		"+44 7911 123456",
+		"447911123456",
+		"07911 123456",
+		"202 555 0199 x1",
+		"202 555 0199 x2",
+		"(202) 555-0199 ext. 2",
+		"+1 202 555 0199 x1",
+		"202 555 0177 x1",
+		"202 555 0177 x2",
+		"n/a",
+		"",
+	} {
+		rows = append(rows, tableRow{Key: i + 1, Value: sql.NullString{String: v, Valid: true}})
+	}
+
+	var got []string
+	for _, g := range findDuplicates(rows, "US") {
+		for _, d := range g.Duplicates {
+			got = append(got, fmt.Sprintf("%v~%v %.1f", d.Key, g.Winner.Key, d.Confidence))
+		}
+	}
+	want := []string{
+		"2~1 1.0", "3~1 0.7", "4~1 1.0",
+		"6~5 0.9", "7~5 0.6",
+		// rows with another extension than the kept one are grouped apart
+		"11~8 1.0", "10~9 1.0",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("findDuplicates(): want %v, got %v", want, got)
+	}
+}
+
+func TestWriteMergeReport(t *testing.T) {
+	groups := []dupeGroup{{
+		Winner: dupeRow{Key: 1, Value: "(202) 555-0123", Normalized: "+12025550123"},
+		Duplicates: []dupeRow{
+			{Key: 3, Value: "202-555-0123 x12", Normalized: "+12025550123x12", Confidence: 0.7, Reason: "same number, with an extension on one only"},
+		},
+	}}
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{reportCSV, `group,role,key,value,normalized,confidence,reason
+1,keep,1,(202) 555-0123,+12025550123,,
+1,duplicate,3,202-555-0123 x12,+12025550123x12,0.70,"same number, with an extension on one only"
+`},
+		{reportJSON, `[
+  {
+    "winner": {
+      "key": 1,
+      "value": "(202) 555-0123",
+      "normalized": "+12025550123"
+    },
+    "duplicates": [
+      {
+        "key": 3,
+        "value": "202-555-0123 x12",
+        "normalized": "+12025550123x12",
+        "confidence": 0.7,
+        "reason": "same number, with an extension on one only"
+      }
+    ]
+  }
+]
+`},
+	}
+	for _, c := range cases {
+		var b strings.Builder
+		if err := writeMergeReport(&b, groups, c.format); err != nil {
+			t.Errorf("writeMergeReport(%s) received an error: %v", c.format, err)
+			continue
+		}
+		if b.String() != c.want {
+			t.Errorf("writeMergeReport(%s): want %s, got %s", c.format, c.want, b.String())
+		}
+	}
+}
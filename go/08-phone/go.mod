@@ -2,4 +2,8 @@ module github.com/ramin0/live/go/phone
 
 go 1.14
 
-require github.com/mattn/go-sqlite3 v2.0.3+incompatible
+require (
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v2.0.3+incompatible
+	golang.org/x/net v0.0.0-20200501053045-e0ff5e5a1de5
+)
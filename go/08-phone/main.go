@@ -1,12 +1,20 @@
+// Command phone cleans up the phone numbers stored in a database:
+//
+//	phone normalize -dsn app.db -table users -column phone [-dry-run]
+//	phone dupes -dsn app.db -table users -column phone [-format csv] [-out merge.csv]
+//	phone scan [-region US] [-json] ticket.txt export.csv page.html
+//	phone seed -dsn phone_numbers.db
+//
+// Run a command with -h for its flags.
 package main
 
 import (
-	"database/sql"
+	"flag"
 	"fmt"
 	"log"
-	"regexp"
+	"os"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/ramin0/live/go/phone/phone"
 )
 
 var (
@@ -23,125 +31,194 @@ var (
 )
 
 func main() {
-	db, err := sql.Open("sqlite3", "phone_numbers.db")
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "normalize":
+		normalizeCmd(os.Args[2:])
+	case "dupes":
+		dupesCmd(os.Args[2:])
+	case "scan":
+		scanCmd(os.Args[2:])
+	case "seed":
+		seedCmd(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: phone <normalize|dupes|scan|seed> [flags]")
+	os.Exit(2)
+}
+
+// databaseFlags are the flags locating the database to connect to
+type databaseFlags struct {
+	DSN    string
+	Driver string
+}
+
+func newDatabaseFlags(fs *flag.FlagSet) *databaseFlags {
+	var f databaseFlags
+	fs.StringVar(&f.DSN, "dsn", "phone_numbers.db", "The database to connect to, either a SQLite file or a Postgres DSN (postgres://... or key=value settings).")
+	fs.StringVar(&f.Driver, "driver", "", "The driver of the database, one of: sqlite3, postgres. Guessed from -dsn by default.")
+	return &f
+}
+
+func (f *databaseFlags) open() (*database, error) {
+	return openDatabase(f.Driver, f.DSN)
+}
+
+// tableFlags defines the flags locating the phone numbers of a table, and
+// picking the row kept among duplicates
+func tableFlags(fs *flag.FlagSet) (*databaseFlags, *normalizeOptions) {
+	var opts normalizeOptions
+	flagDB := newDatabaseFlags(fs)
+	fs.StringVar(&opts.Table, "table", "phone_numbers", "The table holding the phone numbers.")
+	fs.StringVar(&opts.Column, "column", "phone_number", "The column holding the phone numbers.")
+	fs.StringVar(&opts.Key, "key", "", "The column identifying the table's rows. Defaults to rowid for SQLite and ctid for Postgres.")
+	fs.StringVar(&opts.OrderBy, "order-by", "", "The column rows are sorted by to pick the one kept among duplicates. Defaults to -key.")
+	fs.StringVar(&opts.Keep, "keep", keepFirst, "Which row to keep among duplicates, in -order-by order: first or last.")
+	fs.StringVar(&opts.Region, "region", "US", "The region of phone numbers written without a country code.")
+	return flagDB, &opts
+}
+
+func normalizeCmd(args []string) {
+	fs := flag.NewFlagSet("normalize", flag.ExitOnError)
+	flagDB, opts := tableFlags(fs)
+	fs.StringVar(&opts.Format, "format", formatE164, "The format to normalize phone numbers to, one of: e164, national, international.")
+	flagDryRun := fs.Bool("dry-run", false, "Whether to only print the planned updates and deletes, without changing anything.")
+	fs.Parse(args)
+
+	db, err := flagDB.open()
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
 	defer db.Close()
 
-	if _, err := db.Exec(
-		`CREATE TABLE IF NOT EXISTS phone_numbers (phone_number TEXT)`,
-	); err != nil {
-		log.Fatalf("Failed to exec statement: %v", err)
+	plan, err := normalizeTable(db, *opts, *flagDryRun)
+	if err != nil {
+		log.Fatalf("Failed to normalize phone numbers: %v", err)
 	}
+	printPlan(os.Stdout, plan, *flagDryRun)
+}
 
-	if _, err := db.Exec(
-		`DELETE FROM phone_numbers`,
-	); err != nil {
-		log.Fatalf("Failed to exec statement: %v", err)
-	}
+func dupesCmd(args []string) {
+	fs := flag.NewFlagSet("dupes", flag.ExitOnError)
+	flagDB, opts := tableFlags(fs)
+	flagFormat := fs.String("format", reportJSON, "The format of the merge report, one of: json, csv.")
+	flagOut := fs.String("out", "-", "The name of the file to write the merge report to, or - for stdout.")
+	fs.Parse(args)
 
-	stmt, err := db.Prepare(
-		`INSERT INTO phone_numbers (phone_number) VALUES (?)`)
+	db, err := flagDB.open()
 	if err != nil {
-		log.Fatalf("Failed to prepare statement: %v", err)
-	}
-	for _, phoneNumber := range originalPhoneNumbers {
-		if _, err := stmt.Exec(phoneNumber); err != nil {
-			log.Fatalf("Failed to execute statement: %v", err)
-		}
+		log.Fatalf("Failed to open database: %v", err)
 	}
+	defer db.Close()
 
-	fmt.Println("Original:")
-	rows, err := db.Query("SELECT phone_number FROM phone_numbers")
+	groups, err := tableDuplicates(db, *opts)
 	if err != nil {
-		log.Fatalf("Failed to query statement: %v", err)
-	}
-	defer rows.Close()
-	var phoneNumbers []string
-	var formattedPhoneNumbers []string
-	for rows.Next() {
-		var phoneNumber string
-		if err := rows.Scan(&phoneNumber); err != nil {
-			log.Fatalf("Failed to scan rows: %v", err)
-		}
-		formattedPhoneNumber := formatPhoneNumber(phoneNumber)
-		phoneNumbers = append(phoneNumbers, phoneNumber)
-		formattedPhoneNumbers = append(formattedPhoneNumbers, formattedPhoneNumber)
-		fmt.Printf("- %s\n", phoneNumber)
-	}
-	if err := rows.Err(); err != nil {
-		log.Fatalf("Failed to scan statement: %v", err)
+		log.Fatalf("Failed to find duplicates: %v", err)
 	}
 
-	stmtQuery, err := db.Prepare(
-		`SELECT 1 FROM phone_numbers WHERE phone_number = ? LIMIT 1`)
-	if err != nil {
-		log.Fatalf("Failed to prepare statement: %v", err)
+	if *flagOut == "-" {
+		if err := writeMergeReport(os.Stdout, groups, *flagFormat); err != nil {
+			log.Fatalf("Failed to write merge report: %v", err)
+		}
+		return
 	}
-	stmtUpdate, err := db.Prepare(
-		`UPDATE phone_numbers SET phone_number = ? WHERE phone_number = ?`)
+	f, err := os.Create(*flagOut)
 	if err != nil {
-		log.Fatalf("Failed to prepare statement: %v", err)
+		log.Fatalf("Failed to create file: %v", err)
 	}
-	stmtDelete, err := db.Prepare(
-		`DELETE FROM phone_numbers WHERE phone_number = ?`)
-	if err != nil {
-		log.Fatalf("Failed to prepare statement: %v", err)
+	defer f.Close()
+	if err := writeMergeReport(f, groups, *flagFormat); err != nil {
+		log.Fatalf("Failed to write merge report: %v", err)
 	}
-	for i, phoneNumber := range phoneNumbers {
-		formattedPhoneNumber := formattedPhoneNumbers[i]
+	if err := f.Close(); err != nil {
+		log.Fatalf("Failed to write merge report: %v", err)
+	}
+}
 
-		res, err := stmtQuery.Query(formattedPhoneNumber)
-		if err != nil {
-			log.Fatalf("Failed to query statement: %v", err)
+func scanCmd(args []string) {
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	flagRegion := fs.String("region", "US", "The region of phone numbers written without a country code.")
+	flagType := fs.String("type", "", "The kind of the files to scan, one of: text, csv, html. Guessed from their extensions by default, and text for stdin.")
+	flagJSON := fs.Bool("json", false, "Whether to print every match as a JSON object.")
+	fs.Parse(args)
+
+	if _, ok := phone.LookupRegion(*flagRegion); !ok {
+		log.Fatalf("Unknown region %q", *flagRegion)
+	}
+
+	names := fs.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	for _, name := range names {
+		kind := *flagType
+		if kind == "" {
+			kind = scanKind(name)
 		}
-		duplicateFound := res.Next()
-		res.Close()
 
-		if duplicateFound {
-			// log.Printf("Duplicate found for %s, delete %s", formattedPhoneNumber, phoneNumber)
-			if _, err := stmtDelete.Exec(phoneNumber); err != nil {
-				log.Fatalf("Failed to execute statement: %v", err)
+		r := os.Stdin
+		if name != "-" {
+			f, err := os.Open(name)
+			if err != nil {
+				log.Fatalf("Failed to open file: %v", err)
 			}
-			continue
+			r = f
 		}
-
-		// log.Printf("Update %s with %s", phoneNumber, formattedPhoneNumber)
-		if _, err := stmtUpdate.Exec(formattedPhoneNumber, phoneNumber); err != nil {
-			log.Fatalf("Failed to exec statement: %v", err)
+		matches, err := scan(name, r, kind, *flagRegion)
+		r.Close()
+		if err != nil {
+			log.Fatalf("Failed to scan %s: %v", name, err)
+		}
+		if err := printMatches(os.Stdout, matches, *flagJSON); err != nil {
+			log.Fatalf("Failed to print matches: %v", err)
 		}
 	}
+}
+
+func seedCmd(args []string) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	flagDB := newDatabaseFlags(fs)
+	flagTable := fs.String("table", "phone_numbers", "The table to create and fill with sample phone numbers.")
+	fs.Parse(args)
 
-	fmt.Println("Formatted:")
-	rows, err = db.Query("SELECT phone_number FROM phone_numbers ORDER BY phone_number")
+	db, err := flagDB.open()
 	if err != nil {
-		log.Fatalf("Failed to query statement: %v", err)
-	}
-	defer rows.Close()
-	for rows.Next() {
-		var phoneNumber string
-		if err := rows.Scan(&phoneNumber); err != nil {
-			log.Fatalf("Failed to scan rows: %v", err)
-		}
-		fmt.Printf("- %s\n", phoneNumber)
+		log.Fatalf("Failed to open database: %v", err)
 	}
-	if err := rows.Err(); err != nil {
-		log.Fatalf("Failed to scan statement: %v", err)
+	defer db.Close()
+
+	if err := seed(db, *flagTable, originalPhoneNumbers); err != nil {
+		log.Fatalf("Failed to seed phone numbers: %v", err)
 	}
+	fmt.Printf("Seeded %d phone numbers into %s\n", len(originalPhoneNumbers), *flagTable)
 }
 
-func formatPhoneNumber(phoneNumber string) string {
-	// var formattedPhoneNumber string
-	// for _, c := range phoneNumber {
-	// 	if !('0' <= c && c <= '9') {
-	// 		continue
-	// 	}
-	// 	formattedPhoneNumber += string(c)
-	// }
-	// return formattedPhoneNumber
-
-	return regexp.
-		MustCompile("[^\\d]").
-		ReplaceAllString(phoneNumber, "")
+// seed creates table, with a phone_number column, if it doesn't exist, and
+// adds phoneNumbers to it
+func seed(db *database, table string, phoneNumbers []string) error {
+	if _, err := db.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (phone_number TEXT)", quoteIdent(table),
+	)); err != nil {
+		return err
+	}
+	stmt, err := db.Prepare(fmt.Sprintf(
+		"INSERT INTO %s (phone_number) VALUES (%s)", quoteIdent(table), db.placeholder(1),
+	))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, phoneNumber := range phoneNumbers {
+		if _, err := stmt.Exec(phoneNumber); err != nil {
+			return err
+		}
+	}
+	return nil
 }
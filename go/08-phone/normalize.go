@@ -0,0 +1,273 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ramin0/live/go/phone/phone"
+)
+
+// The list of formats phone numbers can be normalized to
+const (
+	formatE164          = "e164"
+	formatNational      = "national"
+	formatInternational = "international"
+)
+
+// The list of rows that can win among duplicates
+const (
+	keepFirst = "first"
+	keepLast  = "last"
+)
+
+type normalizeOptions struct {
+	// Table and Column hold the phone numbers to normalize
+	Table, Column string
+	// Key is the column identifying the table's rows, and defaults to the
+	// database's own row identifier
+	Key string
+	// OrderBy is the column rows are sorted by to pick the one kept among
+	// duplicates, and defaults to Key
+	OrderBy string
+	// Keep is one of keepFirst or keepLast
+	Keep string
+	// Region is the region of numbers written without a country code
+	Region string
+	// Format is one of formatE164, formatNational or formatInternational
+	Format string
+}
+
+// normalizer formats a phone number, and returns the key it's deduped by,
+// which is the same for every way of writing the same number
+type normalizer func(s string) (formatted, key string, err error)
+
+func newNormalizer(region, format string) (normalizer, error) {
+	if _, ok := phone.LookupRegion(region); !ok && region != "" {
+		return nil, fmt.Errorf("unknown region %q", region)
+	}
+	var f func(*phone.Number) string
+	switch format {
+	case formatE164:
+		f = e164
+	case formatNational:
+		f = (*phone.Number).National
+	case formatInternational:
+		f = (*phone.Number).International
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	return func(s string) (string, string, error) {
+		n, err := phone.Parse(s, region)
+		if err != nil {
+			return "", "", err
+		}
+		return f(n), e164(n), nil
+	}, nil
+}
+
+// e164 formats n in E.164, followed by its extension if it has one, as in
+// "+12025550123x123", which phone.Parse reads back
+func e164(n *phone.Number) string {
+	if n.Extension == "" {
+		return n.E164()
+	}
+	return n.E164() + "x" + n.Extension
+}
+
+// tableRow is a phone number and the key of the row it's in
+type tableRow struct {
+	Key   interface{}
+	Value sql.NullString
+}
+
+type plannedUpdate struct {
+	Key      interface{}
+	From, To string
+}
+
+type plannedDelete struct {
+	Key   interface{}
+	Value string
+	// Winner is the key of the row kept instead
+	Winner interface{}
+}
+
+type skippedRow struct {
+	Key   interface{}
+	Value string
+	Err   error
+}
+
+// normalizePlan lists the changes normalizing a table makes
+type normalizePlan struct {
+	Updates []plannedUpdate
+	Deletes []plannedDelete
+	Skipped []skippedRow
+}
+
+// planNormalize plans the updates normalizing rows, and the deletes of the
+// rows duplicating an earlier one. Rows that are empty or can't be parsed are
+// left alone.
+func planNormalize(rows []tableRow, normalize normalizer) *normalizePlan {
+	plan := &normalizePlan{}
+	winners := map[string]interface{}{}
+	for _, r := range rows {
+		if !r.Value.Valid || strings.TrimSpace(r.Value.String) == "" {
+			continue
+		}
+		formatted, key, err := normalize(r.Value.String)
+		if err != nil {
+			plan.Skipped = append(plan.Skipped, skippedRow{Key: r.Key, Value: r.Value.String, Err: err})
+			continue
+		}
+		if winner, ok := winners[key]; ok {
+			plan.Deletes = append(plan.Deletes, plannedDelete{Key: r.Key, Value: r.Value.String, Winner: winner})
+			continue
+		}
+		winners[key] = r.Key
+		if formatted != r.Value.String {
+			plan.Updates = append(plan.Updates, plannedUpdate{Key: r.Key, From: r.Value.String, To: formatted})
+		}
+	}
+	return plan
+}
+
+// normalizeTable normalizes and dedupes the phone numbers of a table in a
+// single transaction, which is rolled back on a dry run
+func normalizeTable(db *database, opts normalizeOptions, dryRun bool) (*normalizePlan, error) {
+	normalize, err := newNormalizer(opts.Region, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	query, err := rowsQuery(db, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := loadRows(tx, query)
+	if err != nil {
+		return nil, err
+	}
+	plan := planNormalize(rows, normalize)
+	if dryRun {
+		return plan, nil
+	}
+
+	table, column, key := quoteIdent(opts.Table), quoteIdent(opts.Column), quoteIdent(opts.Key)
+	// deleting first keeps updates from running into a unique constraint
+	// held by a duplicate
+	stmtDelete, err := tx.Prepare(fmt.Sprintf(
+		"DELETE FROM %s WHERE %s = %s",
+		table, key, db.placeholder(1),
+	))
+	if err != nil {
+		return nil, err
+	}
+	defer stmtDelete.Close()
+	for _, d := range plan.Deletes {
+		if _, err := stmtDelete.Exec(d.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	stmtUpdate, err := tx.Prepare(fmt.Sprintf(
+		"UPDATE %s SET %s = %s WHERE %s = %s",
+		table, column, db.placeholder(1), key, db.placeholder(2),
+	))
+	if err != nil {
+		return nil, err
+	}
+	defer stmtUpdate.Close()
+	for _, u := range plan.Updates {
+		if _, err := stmtUpdate.Exec(u.To, u.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// rowsQuery fills in the defaults of opts, and returns the query selecting
+// the key and phone number of every row, the one to keep among duplicates
+// coming first
+func rowsQuery(db *database, opts *normalizeOptions) (string, error) {
+	if opts.Key == "" {
+		opts.Key = db.defaultKey()
+	}
+	if opts.OrderBy == "" {
+		opts.OrderBy = opts.Key
+	}
+	var direction string
+	switch opts.Keep {
+	case keepFirst, "":
+		direction = "ASC"
+	case keepLast:
+		direction = "DESC"
+	default:
+		return "", fmt.Errorf("unknown row to keep %q", opts.Keep)
+	}
+	key := quoteIdent(opts.Key)
+	return fmt.Sprintf(
+		"SELECT %s, %s FROM %s ORDER BY %s %s, %s ASC",
+		key, quoteIdent(opts.Column), quoteIdent(opts.Table), quoteIdent(opts.OrderBy), direction, key,
+	), nil
+}
+
+// queryer is either a *sql.DB or a *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func loadRows(q queryer, query string) ([]tableRow, error) {
+	rows, err := q.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []tableRow
+	for rows.Next() {
+		var r tableRow
+		if err := rows.Scan(&r.Key, &r.Value); err != nil {
+			return nil, err
+		}
+		// drivers return some keys, like Postgres' ctid, as bytes
+		if b, ok := r.Key.([]byte); ok {
+			r.Key = string(b)
+		}
+		result = append(result, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// printPlan writes a line for every change of plan, followed by a summary
+func printPlan(w io.Writer, plan *normalizePlan, dryRun bool) {
+	for _, d := range plan.Deletes {
+		fmt.Fprintf(w, "delete %v: %q (duplicate of %v)\n", d.Key, d.Value, d.Winner)
+	}
+	for _, u := range plan.Updates {
+		fmt.Fprintf(w, "update %v: %q -> %q\n", u.Key, u.From, u.To)
+	}
+	for _, s := range plan.Skipped {
+		fmt.Fprintf(w, "skip %v: %v\n", s.Key, s.Err)
+	}
+	summary := fmt.Sprintf("%d updated, %d deleted, %d skipped", len(plan.Updates), len(plan.Deletes), len(plan.Skipped))
+	if dryRun {
+		summary += " (dry run, nothing was changed)"
+	}
+	fmt.Fprintln(w, summary)
+}
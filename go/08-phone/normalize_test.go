@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestDatabase(t *testing.T) (*database, func()) {
+	dir, err := ioutil.TempDir("", "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := openDatabase(driverSQLite, filepath.Join(dir, "phone_numbers.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestDetectDriver(t *testing.T) {
+	cases := []struct {
+		dsn    string
+		driver string
+	}{
+		{dsn: "phone_numbers.db", driver: driverSQLite},
+		{dsn: "/tmp/my data/phone=numbers.db", driver: driverSQLite},
+		{dsn: "file:phone_numbers.db?cache=shared", driver: driverSQLite},
+		{dsn: "", driver: driverSQLite},
+		{dsn: "postgres://localhost/app", driver: driverPostgres},
+		{dsn: "postgresql://localhost/app", driver: driverPostgres},
+		{dsn: "host=localhost dbname=app", driver: driverPostgres},
+		{dsn: "host=localhost user=app sslmode=disable", driver: driverPostgres},
+		{dsn: "service=app", driver: driverPostgres},
+	}
+	for _, c := range cases {
+		if got := detectDriver(c.dsn); got != c.driver {
+			t.Errorf("detectDriver(%q): want %s, got %s", c.dsn, c.driver, got)
+		}
+	}
+
+	if _, err := openDatabase("mysql", "phone_numbers.db"); err == nil {
+		t.Errorf("openDatabase(): expected an error for an unknown driver")
+	}
+}
+
+func tableValues(t *testing.T, db *database) []string {
+	rows, err := db.Query(`SELECT phone_number FROM phone_numbers ORDER BY rowid`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestNormalizeTable(t *testing.T) {
+	cases := []struct {
+		opts    normalizeOptions
+		dryRun  bool
+		updates int
+		deletes int
+		want    []string
+	}{
+		{
+			opts:    normalizeOptions{Keep: keepFirst, Format: formatE164},
+			updates: 6, deletes: 3,
+			want: []string{"+11234567890", "+11234567891", "+11234567892", "+11234567893", "+11234567894", "n/a", "+12025550123x12"},
+		},
+		{
+			// (123) 456-7893 is already in the national format
+			opts:    normalizeOptions{Keep: keepLast, Format: formatNational},
+			updates: 5, deletes: 3,
+			want: []string{"(123) 456-7891", "(123) 456-7893", "(123) 456-7894", "(123) 456-7890", "(123) 456-7892", "n/a", "(202) 555-0123 ext. 12"},
+		},
+		{
+			opts:    normalizeOptions{Keep: keepFirst, Format: formatE164},
+			dryRun:  true,
+			updates: 6, deletes: 3,
+			want: append(append([]string{}, originalPhoneNumbers...), "n/a", "+1 202 555 0123 x12"),
+		},
+	}
+	for _, c := range cases {
+		db, teardown := newTestDatabase(t)
+		if err := seed(db, "phone_numbers", append(append([]string{}, originalPhoneNumbers...), "n/a", "+1 202 555 0123 x12")); err != nil {
+			teardown()
+			t.Fatalf("seed() received an error: %v", err)
+		}
+
+		c.opts.Table, c.opts.Column, c.opts.Region = "phone_numbers", "phone_number", "US"
+		plan, err := normalizeTable(db, c.opts, c.dryRun)
+		if err != nil {
+			t.Errorf("normalizeTable(%+v) received an error: %v", c.opts, err)
+			teardown()
+			continue
+		}
+		if len(plan.Updates) != c.updates || len(plan.Deletes) != c.deletes || len(plan.Skipped) != 1 {
+			t.Errorf("normalizeTable(%+v): want %d updates, %d deletes and 1 skipped, got %+v", c.opts, c.updates, c.deletes, plan)
+		}
+		if got := tableValues(t, db); fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("normalizeTable(%+v): want %q, got %q", c.opts, c.want, got)
+		}
+		teardown()
+	}
+}
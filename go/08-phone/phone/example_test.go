@@ -0,0 +1,21 @@
+package phone_test
+
+import (
+	"fmt"
+
+	"github.com/ramin0/live/go/phone/phone"
+)
+
+func ExampleParse() {
+	n, err := phone.Parse("+44 (0)7911 123456 ext. 12", "US")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(n.E164())
+	fmt.Println(n.National())
+	fmt.Println(n.International())
+	// Output:
+	// +447911123456
+	// 07911 123456 ext. 12
+	// +44 7911 123456 ext. 12
+}
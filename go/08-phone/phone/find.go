@@ -0,0 +1,40 @@
+package phone
+
+import "regexp"
+
+// candidateRegexp matches anything that looks like a phone number, to be
+// confirmed by Parse: digits with single separators between them, an
+// optional leading "+" or area code in parentheses, and an optional extension
+var candidateRegexp = regexp.MustCompile(`(?i)(?:\+\s?)?(?:\(\d{1,5}\)[ .\-]?)?\d(?:[ .\-/]?\(?\d\)?){5,16}(?:\s*(?:ext\.?|x|#)\s*\d{1,7})?`)
+
+// Match is a phone number found in a text
+type Match struct {
+	// Start and End are the byte offsets of the number in the text
+	Start, End int
+	// Text is the number as it's written in the text
+	Text   string
+	Number *Number
+}
+
+// Find returns the phone numbers written in text, parsing those without a
+// country code as numbers of defaultRegion. Runs of digits that aren't valid
+// phone numbers, or are part of a longer word, are left out.
+func Find(text, defaultRegion string) []Match {
+	var matches []Match
+	for _, loc := range candidateRegexp.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if start > 0 && isWordByte(text[start-1]) || end < len(text) && isWordByte(text[end]) {
+			continue
+		}
+		n, err := Parse(text[start:end], defaultRegion)
+		if err != nil {
+			continue
+		}
+		matches = append(matches, Match{Start: start, End: end, Text: text[start:end], Number: n})
+	}
+	return matches
+}
+
+func isWordByte(b byte) bool {
+	return '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || b == '_'
+}
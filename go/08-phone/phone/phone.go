@@ -0,0 +1,173 @@
+// Package phone parses phone numbers written in the many ways people write
+// them, and formats them consistently
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The list of errors Parse can return, wrapped with the number that caused
+// them
+var (
+	ErrEmpty              = errors.New("empty number")
+	ErrInvalidCharacters  = errors.New("invalid characters")
+	ErrUnknownRegion      = errors.New("unknown region")
+	ErrNoRegion           = errors.New("national number without a region")
+	ErrUnknownCountryCode = errors.New("unknown country code")
+	ErrTooShort           = errors.New("too short")
+	ErrTooLong            = errors.New("too long")
+)
+
+var (
+	extensionRegexp = regexp.MustCompile(`(?i)\s*(?:;\s*ext=|extension|ext\.?|x|#)\s*(\d{1,7})\s*$`)
+	numberRegexp    = regexp.MustCompile(`^\+?[\d\s().\-/]+$`)
+)
+
+// Number is a parsed phone number
+type Number struct {
+	// Region is the code of the region the number belongs to, as in "US"
+	Region string
+	// CountryCode is the calling code of the number's region, as in "1"
+	CountryCode string
+	// NationalNumber is the number without its country code, trunk prefix
+	// and extension, as in "2025550123"
+	NationalNumber string
+	// Extension is the extension to ask for once the call is answered, if
+	// any
+	Extension string
+}
+
+// Parse parses a phone number, as in "(202) 555-0123 x123". Numbers starting
+// with "+", "00" or the international prefix of defaultRegion are parsed as
+// international numbers, while any other number is parsed as a national
+// number of defaultRegion, which can be empty if there's no such thing.
+func Parse(s, defaultRegion string) (*Number, error) {
+	n, err := parse(strings.TrimSpace(s), defaultRegion)
+	if err != nil {
+		return nil, fmt.Errorf("phone: parsing %q: %w", s, err)
+	}
+	return n, nil
+}
+
+func parse(s, defaultRegion string) (*Number, error) {
+	if s == "" {
+		return nil, ErrEmpty
+	}
+
+	var def *Region
+	if defaultRegion != "" {
+		r, ok := LookupRegion(defaultRegion)
+		if !ok {
+			return nil, ErrUnknownRegion
+		}
+		def = r
+	}
+
+	var ext string
+	if m := extensionRegexp.FindStringSubmatchIndex(s); m != nil {
+		ext = s[m[2]:m[3]]
+		s = s[:m[0]]
+	}
+	if !numberRegexp.MatchString(s) {
+		return nil, ErrInvalidCharacters
+	}
+
+	intl := strings.HasPrefix(s, "+")
+	digits := strings.Map(func(r rune) rune {
+		if '0' <= r && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+	if !intl {
+		switch {
+		case strings.HasPrefix(digits, "00"):
+			intl, digits = true, digits[2:]
+		case def != nil && def.IntlPrefix != "" && strings.HasPrefix(digits, def.IntlPrefix):
+			intl, digits = true, digits[len(def.IntlPrefix):]
+		}
+	}
+
+	region := def
+	if intl {
+		region = nil
+		// calling codes are prefix-free, and at most 3 digits long
+		for i := 1; i <= 3 && i <= len(digits); i++ {
+			if r, ok := lookupCountryCode(digits[:i]); ok {
+				region = r
+				break
+			}
+		}
+		if region == nil {
+			return nil, ErrUnknownCountryCode
+		}
+		// keep the default region when it shares the number's calling code
+		if def != nil && def.CountryCode == region.CountryCode {
+			region = def
+		}
+		digits = digits[len(region.CountryCode):]
+	}
+	if region == nil {
+		return nil, ErrNoRegion
+	}
+
+	// the trunk prefix is sometimes kept after the calling code too, as in
+	// "+44 (0)20 7946 0018"
+	if t := region.TrunkPrefix; t != "" && strings.HasPrefix(digits, t) &&
+		len(digits)-len(t) >= region.MinLength && len(digits)-len(t) <= region.MaxLength {
+		digits = digits[len(t):]
+	}
+	if len(digits) < region.MinLength {
+		return nil, ErrTooShort
+	}
+	if len(digits) > region.MaxLength {
+		return nil, ErrTooLong
+	}
+
+	return &Number{
+		Region:         region.Code,
+		CountryCode:    region.CountryCode,
+		NationalNumber: digits,
+		Extension:      ext,
+	}, nil
+}
+
+// E164 formats the number as in "+12025550123". E.164 has no room for
+// extensions, so they're left out.
+func (n *Number) E164() string {
+	return "+" + n.CountryCode + n.NationalNumber
+}
+
+// National formats the number as it's dialed from within its region, as in
+// "(202) 555-0123 ext. 123"
+func (n *Number) National() string {
+	s := n.NationalNumber
+	if r, ok := LookupRegion(n.Region); ok {
+		if s, ok = format(n.NationalNumber, r.NationalFormats); !ok {
+			s = r.TrunkPrefix + n.NationalNumber
+		}
+	}
+	return s + n.extension()
+}
+
+// International formats the number as it's dialed from abroad, as in
+// "+1 202-555-0123 ext. 123"
+func (n *Number) International() string {
+	s := n.NationalNumber
+	if r, ok := LookupRegion(n.Region); ok {
+		if f, ok := format(n.NationalNumber, r.IntlFormats); ok {
+			s = f
+		}
+	}
+	return "+" + n.CountryCode + " " + s + n.extension()
+}
+
+func (n *Number) extension() string {
+	if n.Extension == "" {
+		return ""
+	}
+	return " ext. " + n.Extension
+}
@@ -0,0 +1,85 @@
+package phone
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		input         string
+		region        string
+		e164          string
+		national      string
+		international string
+		err           error
+	}{
+		{"1234567890", "US", "+11234567890", "(123) 456-7890", "+1 123-456-7890", nil},
+		{"(123)456-7892", "US", "+11234567892", "(123) 456-7892", "+1 123-456-7892", nil},
+		{"1 202 555 0123", "US", "+12025550123", "(202) 555-0123", "+1 202-555-0123", nil},
+		{"+1 (202) 555-0123 x123", "", "+12025550123", "(202) 555-0123 ext. 123", "+1 202-555-0123 ext. 123", nil},
+		{"202.555.0123 ext. 45", "US", "+12025550123", "(202) 555-0123 ext. 45", "+1 202-555-0123 ext. 45", nil},
+		{"011 44 7911 123456", "US", "+447911123456", "07911 123456", "+44 7911 123456", nil},
+		{"00 44 7911 123456", "FR", "+447911123456", "07911 123456", "+44 7911 123456", nil},
+		{"07911 123456", "GB", "+447911123456", "07911 123456", "+44 7911 123456", nil},
+		{"+44 (0)7911 123456", "", "+447911123456", "07911 123456", "+44 7911 123456", nil},
+		{"01 23 45 67 89", "FR", "+33123456789", "01 23 45 67 89", "+33 1 23 45 67 89", nil},
+		{"010 1234 5678", "EG", "+201012345678", "010 1234 5678", "+20 10 1234 5678", nil},
+		{"+39 06 1234 5678", "", "+390612345678", "0612345678", "+39 0612345678", nil},
+		{"+1 416 555 0199", "CA", "+14165550199", "(416) 555-0199", "+1 416-555-0199", nil},
+		{"", "US", "", "", "", ErrEmpty},
+		{"555-CALL-NOW", "US", "", "", "", ErrInvalidCharacters},
+		{"555 0123", "US", "", "", "", ErrTooShort},
+		{"202 555 01234", "US", "", "", "", ErrTooLong},
+		{"202 555 0123", "", "", "", "", ErrNoRegion},
+		{"202 555 0123", "XX", "", "", "", ErrUnknownRegion},
+		{"+999 1234 5678", "", "", "", "", ErrUnknownCountryCode},
+	}
+	for _, c := range cases {
+		n, err := Parse(c.input, c.region)
+		if c.err != nil {
+			if !errors.Is(err, c.err) {
+				t.Errorf("Parse(%q, %q): want error %v, got %v", c.input, c.region, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q, %q) received an error: %v", c.input, c.region, err)
+			continue
+		}
+		if got := n.E164(); got != c.e164 {
+			t.Errorf("Parse(%q, %q).E164(): want %q, got %q", c.input, c.region, c.e164, got)
+		}
+		if got := n.National(); got != c.national {
+			t.Errorf("Parse(%q, %q).National(): want %q, got %q", c.input, c.region, c.national, got)
+		}
+		if got := n.International(); got != c.international {
+			t.Errorf("Parse(%q, %q).International(): want %q, got %q", c.input, c.region, c.international, got)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{"Call me at (202) 555-0123 x12 or +44 7911 123456.", []string{"(202) 555-0123 x12 +12025550123", "+44 7911 123456 +447911123456"}},
+		{"order #123456789012345, id abc2025550123", nil},
+		{"born 2020-05-01, paid $1,250.00", nil},
+		{"202.555.0123\n202-555-0199", []string{"202.555.0123 +12025550123", "202-555-0199 +12025550199"}},
+	}
+	for _, c := range cases {
+		var got []string
+		for _, m := range Find(c.text, "US") {
+			if c.text[m.Start:m.End] != m.Text {
+				t.Errorf("Find(%q): match %q is at the wrong offsets %d-%d", c.text, m.Text, m.Start, m.End)
+			}
+			got = append(got, m.Text+" "+m.Number.E164())
+		}
+		if strings.Join(got, ",") != strings.Join(c.want, ",") {
+			t.Errorf("Find(%q): want %q, got %q", c.text, c.want, got)
+		}
+	}
+}
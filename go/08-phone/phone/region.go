@@ -0,0 +1,163 @@
+package phone
+
+import "strings"
+
+// Region describes how phone numbers are dialed and written in a region
+type Region struct {
+	// Code is the region's ISO 3166-1 alpha-2 code, as in "US"
+	Code string
+	// CountryCode is the calling code dialed after an international prefix,
+	// as in "1"
+	CountryCode string
+	// IntlPrefix is dialed to call other countries from the region, on top
+	// of the "+" and "00" prefixes understood everywhere
+	IntlPrefix string
+	// TrunkPrefix is dialed before national numbers from within the region,
+	// and dropped when calling from abroad
+	TrunkPrefix string
+	// MinLength and MaxLength bound the length of the region's national
+	// significant numbers, without the trunk prefix
+	MinLength, MaxLength int
+	// NationalFormats and IntlFormats are patterns for writing national
+	// significant numbers, where every X stands for a digit. The first one
+	// with as many Xs as a number has digits is used.
+	NationalFormats, IntlFormats []string
+}
+
+// regions are the regions numbers can be parsed for. Regions sharing a
+// calling code are listed after the one their numbers are attributed to.
+var regions = []*Region{
+	{
+		Code: "US", CountryCode: "1", IntlPrefix: "011", TrunkPrefix: "1",
+		MinLength: 10, MaxLength: 10,
+		NationalFormats: []string{"(XXX) XXX-XXXX"},
+		IntlFormats:     []string{"XXX-XXX-XXXX"},
+	},
+	{
+		Code: "CA", CountryCode: "1", IntlPrefix: "011", TrunkPrefix: "1",
+		MinLength: 10, MaxLength: 10,
+		NationalFormats: []string{"(XXX) XXX-XXXX"},
+		IntlFormats:     []string{"XXX-XXX-XXXX"},
+	},
+	{
+		Code: "GB", CountryCode: "44", IntlPrefix: "00", TrunkPrefix: "0",
+		MinLength: 9, MaxLength: 10,
+		NationalFormats: []string{"0XXXX XXXXXX", "0XXXX XXXXX"},
+		IntlFormats:     []string{"XXXX XXXXXX", "XXXX XXXXX"},
+	},
+	{
+		Code: "DE", CountryCode: "49", IntlPrefix: "00", TrunkPrefix: "0",
+		MinLength: 6, MaxLength: 13,
+		NationalFormats: []string{"0XXXX XXXXXXX", "0XXX XXXXXXX", "0XX XXXXXXXX"},
+		IntlFormats:     []string{"XXXX XXXXXXX", "XXX XXXXXXX", "XX XXXXXXXX"},
+	},
+	{
+		Code: "FR", CountryCode: "33", IntlPrefix: "00", TrunkPrefix: "0",
+		MinLength: 9, MaxLength: 9,
+		NationalFormats: []string{"0X XX XX XX XX"},
+		IntlFormats:     []string{"X XX XX XX XX"},
+	},
+	{
+		// Italian numbers keep their leading 0 when dialed from abroad
+		Code: "IT", CountryCode: "39", IntlPrefix: "00",
+		MinLength: 6, MaxLength: 11,
+	},
+	{
+		Code: "ES", CountryCode: "34", IntlPrefix: "00",
+		MinLength: 9, MaxLength: 9,
+		NationalFormats: []string{"XXX XX XX XX"},
+		IntlFormats:     []string{"XXX XX XX XX"},
+	},
+	{
+		Code: "NL", CountryCode: "31", IntlPrefix: "00", TrunkPrefix: "0",
+		MinLength: 9, MaxLength: 9,
+		NationalFormats: []string{"0XX XXX XXXX"},
+		IntlFormats:     []string{"XX XXX XXXX"},
+	},
+	{
+		Code: "EG", CountryCode: "20", IntlPrefix: "00", TrunkPrefix: "0",
+		MinLength: 8, MaxLength: 10,
+		NationalFormats: []string{"0XX XXXX XXXX", "0X XXXX XXXX", "0X XXX XXXX"},
+		IntlFormats:     []string{"XX XXXX XXXX", "X XXXX XXXX", "X XXX XXXX"},
+	},
+	{
+		Code: "IN", CountryCode: "91", IntlPrefix: "00", TrunkPrefix: "0",
+		MinLength: 10, MaxLength: 10,
+		NationalFormats: []string{"0XXXXX XXXXX"},
+		IntlFormats:     []string{"XXXXX XXXXX"},
+	},
+	{
+		Code: "CN", CountryCode: "86", IntlPrefix: "00", TrunkPrefix: "0",
+		MinLength: 7, MaxLength: 11,
+		NationalFormats: []string{"0XXX XXXX XXXX"},
+		IntlFormats:     []string{"XXX XXXX XXXX"},
+	},
+	{
+		Code: "JP", CountryCode: "81", IntlPrefix: "010", TrunkPrefix: "0",
+		MinLength: 9, MaxLength: 10,
+		NationalFormats: []string{"0XX-XXXX-XXXX", "0X-XXXX-XXXX"},
+		IntlFormats:     []string{"XX-XXXX-XXXX", "X-XXXX-XXXX"},
+	},
+	{
+		Code: "AU", CountryCode: "61", IntlPrefix: "0011", TrunkPrefix: "0",
+		MinLength: 9, MaxLength: 9,
+		NationalFormats: []string{"0X XXXX XXXX"},
+		IntlFormats:     []string{"X XXXX XXXX"},
+	},
+	{
+		Code: "BR", CountryCode: "55", IntlPrefix: "00", TrunkPrefix: "0",
+		MinLength: 10, MaxLength: 11,
+		NationalFormats: []string{"(XX) XXXXX-XXXX", "(XX) XXXX-XXXX"},
+		IntlFormats:     []string{"XX XXXXX-XXXX", "XX XXXX-XXXX"},
+	},
+	{
+		Code: "MX", CountryCode: "52", IntlPrefix: "00",
+		MinLength: 10, MaxLength: 10,
+		NationalFormats: []string{"XX XXXX XXXX"},
+		IntlFormats:     []string{"XX XXXX XXXX"},
+	},
+}
+
+// LookupRegion returns the region with the given ISO 3166-1 alpha-2 code
+func LookupRegion(code string) (*Region, bool) {
+	code = strings.ToUpper(code)
+	for _, r := range regions {
+		if r.Code == code {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// lookupCountryCode returns the region the numbers with the given calling
+// code are attributed to
+func lookupCountryCode(cc string) (*Region, bool) {
+	for _, r := range regions {
+		if r.CountryCode == cc {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// format writes nsn using the first of patterns with as many Xs as it has
+// digits, or returns false if there's none
+func format(nsn string, patterns []string) (string, bool) {
+	for _, p := range patterns {
+		if strings.Count(p, "X") != len(nsn) {
+			continue
+		}
+		var b strings.Builder
+		i := 0
+		for _, c := range p {
+			if c == 'X' {
+				b.WriteByte(nsn[i])
+				i++
+				continue
+			}
+			b.WriteRune(c)
+		}
+		return b.String(), true
+	}
+	return "", false
+}
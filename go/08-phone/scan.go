@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ramin0/live/go/phone/phone"
+	"golang.org/x/net/html"
+)
+
+// The list of kinds of files phone numbers can be scanned for
+const (
+	scanText = "text"
+	scanCSV  = "csv"
+	scanHTML = "html"
+)
+
+// scanMatch is a phone number found in a file. Matches in text and HTML files
+// are located by Line and Column, while matches in CSV files are located by
+// Row, Field and the Column in that field, all counting from 1.
+type scanMatch struct {
+	Source     string `json:"source"`
+	Line       int    `json:"line,omitempty"`
+	Row        int    `json:"row,omitempty"`
+	Field      int    `json:"field,omitempty"`
+	Column     int    `json:"column,omitempty"`
+	Text       string `json:"text"`
+	Normalized string `json:"normalized"`
+	// Tel is whether the number is the href of a tel: link
+	Tel bool `json:"tel,omitempty"`
+}
+
+// scanKind guesses the kind of a file from its extension
+func scanKind(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return scanCSV
+	case ".html", ".htm":
+		return scanHTML
+	}
+	return scanText
+}
+
+// scan returns the phone numbers found in r, which is a kind file
+func scan(source string, r io.Reader, kind, region string) ([]scanMatch, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case scanText:
+		return scanTextMatches(source, string(b), region, nil), nil
+	case scanCSV:
+		return scanCSVMatches(source, b, region)
+	case scanHTML:
+		return scanHTMLMatches(source, string(b), region)
+	}
+	return nil, fmt.Errorf("unknown kind of file %q", kind)
+}
+
+// scanTextMatches returns the phone numbers in text, except those
+// overlapping one of the skipped [start, end) ranges
+func scanTextMatches(source, text, region string, skipped [][2]int) []scanMatch {
+	var matches []scanMatch
+	for _, m := range phone.Find(text, region) {
+		if overlaps(m.Start, m.End, skipped) {
+			continue
+		}
+		line, column := position(text, m.Start)
+		matches = append(matches, scanMatch{
+			Source:     source,
+			Line:       line,
+			Column:     column,
+			Text:       m.Text,
+			Normalized: e164(m.Number),
+		})
+	}
+	return matches
+}
+
+func scanCSVMatches(source string, b []byte, region string) ([]scanMatch, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.FieldsPerRecord = -1
+	var matches []scanMatch
+	for row := 1; ; row++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, field := range record {
+			for _, m := range phone.Find(field, region) {
+				matches = append(matches, scanMatch{
+					Source:     source,
+					Row:        row,
+					Field:      i + 1,
+					Column:     utf8.RuneCountInString(field[:m.Start]) + 1,
+					Text:       m.Text,
+					Normalized: e164(m.Number),
+				})
+			}
+		}
+	}
+	return matches, nil
+}
+
+// hrefAttr matches the href attribute of a raw tag, its value being the
+// first of the submatches that isn't empty, depending on how it's quoted
+var hrefAttr = regexp.MustCompile(`(?i)[\s/]href\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+))`)
+
+// scanHTMLMatches returns the phone numbers of the tel: links of an HTML
+// page, followed by the ones written anywhere else in it. A tel: link whose
+// href can't be found in the page, which shouldn't happen, is returned
+// without a position.
+func scanHTMLMatches(source, page, region string) ([]scanMatch, error) {
+	var matches []scanMatch
+	// the tags of the tel: links, whose numbers aren't matched again
+	var tags [][2]int
+	z := html.NewTokenizer(strings.NewReader(page))
+	for offset := 0; ; {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				return nil, z.Err()
+			}
+			break
+		}
+		raw := string(z.Raw())
+		start := offset
+		offset += len(raw)
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tok := z.Token()
+		if tok.Data != "a" {
+			continue
+		}
+
+		var href string
+		for _, a := range tok.Attr {
+			if a.Namespace == "" && a.Key == "href" {
+				href = strings.TrimSpace(a.Val)
+				break
+			}
+		}
+		if len(href) < 4 || !strings.EqualFold(href[:4], "tel:") {
+			continue
+		}
+		n, err := parseTel(href[4:], region)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, [2]int{start, offset})
+		m := scanMatch{Source: source, Text: href, Normalized: e164(n), Tel: true}
+		if loc := hrefAttr.FindStringSubmatchIndex(raw); loc != nil {
+			for i := 2; i < len(loc); i += 2 {
+				if loc[i] >= 0 {
+					m.Line, m.Column = position(page, start+loc[i])
+					break
+				}
+			}
+		}
+		matches = append(matches, m)
+	}
+
+	return append(matches, scanTextMatches(source, page, region, tags)...), nil
+}
+
+// parseTel parses the number of a tel: uri, as in "+1-202-555-0123;ext=12",
+// ignoring its parameters other than the extension
+func parseTel(s, region string) (*phone.Number, error) {
+	if u, err := url.PathUnescape(s); err == nil {
+		s = u
+	}
+	parts := strings.Split(s, ";")
+	number := parts[0]
+	for _, p := range parts[1:] {
+		if strings.HasPrefix(strings.ToLower(p), "ext=") {
+			number += " x" + p[4:]
+		}
+	}
+	return phone.Parse(number, region)
+}
+
+func overlaps(start, end int, ranges [][2]int) bool {
+	for _, r := range ranges {
+		if start < r[1] && r[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// position returns the line and column of the byte at offset in text
+func position(text string, offset int) (int, int) {
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	lineStart := strings.LastIndex(before, "\n") + 1
+	return line, utf8.RuneCountInString(before[lineStart:]) + 1
+}
+
+// printMatches writes a line for every match, or a JSON object if asJSON is
+// true
+func printMatches(w io.Writer, matches []scanMatch, asJSON bool) error {
+	enc := json.NewEncoder(w)
+	for _, m := range matches {
+		if asJSON {
+			if err := enc.Encode(m); err != nil {
+				return err
+			}
+			continue
+		}
+		var err error
+		switch {
+		case m.Row > 0:
+			_, err = fmt.Fprintf(w, "%s:row %d:field %d:%d: %q %s\n", m.Source, m.Row, m.Field, m.Column, m.Text, m.Normalized)
+		case m.Line == 0:
+			_, err = fmt.Fprintf(w, "%s: %q %s\n", m.Source, m.Text, m.Normalized)
+		default:
+			_, err = fmt.Fprintf(w, "%s:%d:%d: %q %s\n", m.Source, m.Line, m.Column, m.Text, m.Normalized)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	cases := []struct {
+		kind  string
+		input string
+		want  []string
+	}{
+		{
+			kind:  scanText,
+			input: "Hi,\nmy number is (202) 555-0123 x12, order #20200501.\nThanks",
+			want:  []string{"t:2:14: \"(202) 555-0123 x12\" +12025550123x12"},
+		},
+		{
+			kind:  scanCSV,
+			input: "name,phone\nJane,\"home: 202 555 0123\"\nJoe,+44 7911 123456\n",
+			want: []string{
+				"t:row 2:field 2:7: \"202 555 0123\" +12025550123",
+				"t:row 3:field 2:1: \"+44 7911 123456\" +447911123456",
+			},
+		},
+		{
+			kind:  scanHTML,
+			input: "<p>Call\n<a href=\"tel:+1-202-555-0123;ext=12\">us</a> or 202.555.0199</p>",
+			want: []string{
+				"t:2:10: \"tel:+1-202-555-0123;ext=12\" +12025550123x12",
+				"t:2:48: \"202.555.0199\" +12025550199",
+			},
+		},
+		{
+			// hrefs are found however they're written, and their numbers
+			// aren't matched again as text
+			kind:  scanHTML,
+			input: "<a href = 'tel:+1&#45;202-555-0123'>a</a>\n<A HREF=tel:2025550199>b</A>",
+			want: []string{
+				"t:1:12: \"tel:+1-202-555-0123\" +12025550123",
+				"t:2:9: \"tel:2025550199\" +12025550199",
+			},
+		},
+	}
+	for _, c := range cases {
+		matches, err := scan("t", strings.NewReader(c.input), c.kind, "US")
+		if err != nil {
+			t.Errorf("scan(%q) received an error: %v", c.input, err)
+			continue
+		}
+		var b strings.Builder
+		if err := printMatches(&b, matches, false); err != nil {
+			t.Fatalf("printMatches() received an error: %v", err)
+		}
+		got := strings.Split(strings.TrimSpace(b.String()), "\n")
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("scan(%q): want %q, got %q", c.input, c.want, got)
+		}
+	}
+}
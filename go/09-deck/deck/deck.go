@@ -18,6 +18,14 @@ const (
 	SuitDiamonds
 	SuitClubs
 	SuitJoker
+	// the suits of Spanish decks
+	SuitCoins
+	SuitCups
+	SuitSwords
+	SuitBatons
+	// the trumps of Tarot decks, numbered from 1 to 21, and the excuse,
+	// numbered 0
+	SuitTrumps
 )
 
 func (s Suit) String() string {
@@ -32,6 +40,43 @@ func (s Suit) String() string {
 		return "♣"
 	case SuitJoker:
 		return "J"
+	case SuitCoins:
+		return "O"
+	case SuitCups:
+		return "P"
+	case SuitSwords:
+		return "E"
+	case SuitBatons:
+		return "B"
+	case SuitTrumps:
+		return "T"
+	}
+	return ""
+}
+
+// ASCII returns the letter of the Suit, as used in short notation like "As"
+func (s Suit) ASCII() string {
+	switch s {
+	case SuitSpades:
+		return "s"
+	case SuitHearts:
+		return "h"
+	case SuitDiamonds:
+		return "d"
+	case SuitClubs:
+		return "c"
+	case SuitJoker:
+		return "J"
+	case SuitCoins:
+		return "o"
+	case SuitCups:
+		return "p"
+	case SuitSwords:
+		return "e"
+	case SuitBatons:
+		return "b"
+	case SuitTrumps:
+		return "T"
 	}
 	return ""
 }
@@ -42,7 +87,8 @@ type Value int
 // The list of Values that can be assigned to a Card
 const (
 	_ Value = iota
-	_
+	// ValueOne is the low ace of Tarot decks
+	ValueOne
 	ValueTwo
 	ValueThree
 	ValueFour
@@ -56,6 +102,9 @@ const (
 	ValueQueen
 	ValueKing
 	ValueAce
+	// ValueKnight ranks between the Jack and the Queen, as in Tarot decks,
+	// or the Jack and the King, as in Spanish decks
+	ValueKnight
 )
 
 func (v Value) String() string {
@@ -68,6 +117,8 @@ func (v Value) String() string {
 		return "K"
 	case ValueAce:
 		return "A"
+	case ValueKnight:
+		return "C"
 	default:
 		return strconv.Itoa(int(v))
 	}
@@ -80,33 +131,64 @@ type Card struct {
 }
 
 func (c Card) String() string {
-	if c.Suit == SuitJoker {
-		return "[JOKER]"
+	switch c.Suit {
+	case SuitJoker:
+		return "[" + c.jokerName() + "]"
+	case SuitTrumps:
+		return c.trumpName()
 	}
 	return fmt.Sprintf("[%v  %-2v]", c.Suit, c.Value)
 }
 
+// ASCII is like String, with letters instead of the suit symbols, for
+// terminals without Unicode
+func (c Card) ASCII() string {
+	switch c.Suit {
+	case SuitJoker, SuitTrumps:
+		return c.String()
+	}
+	return fmt.Sprintf("[%v  %-2v]", c.Suit.ASCII(), c.Value)
+}
+
+// trumpName numbers trumps rather than naming their Values, as the 11th
+// trump isn't a Jack
+func (c Card) trumpName() string {
+	if c.Value == 0 {
+		return "[EXCUSE]"
+	}
+	return fmt.Sprintf("[T  %-2d]", int(c.Value))
+}
+
+// jokerName tells jokers apart by their Value, which numbers them from 1,
+// while jokers numbered 0 are all alike
+func (c Card) jokerName() string {
+	if c.Value == 0 {
+		return "JOKER"
+	}
+	return "JOKER" + strconv.Itoa(int(c.Value))
+}
+
 // Option defines a way to manipulate a deck of Cards
 type Option func([]Card) []Card
 
-// New creates a new deck with the specified Options
+// New creates a new 52-card French deck with the specified Options
 func New(opts ...Option) []Card {
-	var deck []Card
-	for suit := SuitSpades; suit <= SuitClubs; suit++ {
-		for value := ValueTwo; value <= ValueAce; value++ {
-			deck = append(deck, Card{Suit: suit, Value: value})
-		}
-	}
-	for _, opt := range opts {
-		deck = opt(deck)
-	}
-	return deck
+	return French.New(opts...)
 }
 
 // SortDefault provides the default sorting logic for a deck
 func SortDefault(i, j Card) bool {
 	return i.Suit < j.Suit ||
-		i.Suit == j.Suit && i.Value < j.Value
+		i.Suit == j.Suit && i.Value.order() < j.Value.order()
+}
+
+// order ranks Values for sorting, which only differs from their numbers for
+// knights
+func (v Value) order() int {
+	if v == ValueKnight {
+		return 2*int(ValueJack) + 1
+	}
+	return 2 * int(v)
 }
 
 // OptionSort can sort a deck based on the sorting function fn
@@ -119,21 +201,41 @@ func OptionSort(fn func(Card, Card) bool) Option {
 	}
 }
 
-// OptionShuffle shuffles a deck
+// OptionShuffle shuffles a deck with a source seeded once per process, so
+// deals differ from one run to the next
 func OptionShuffle() Option {
+	return OptionShuffleWithSource(defaultSource)
+}
+
+// OptionShuffleWithSource shuffles a deck with src, so a shuffle can be
+// replayed by using a source with the same seed
+func OptionShuffleWithSource(src rand.Source) Option {
 	return func(deck []Card) []Card {
-		rand.Shuffle(len(deck), func(i, j int) {
+		rand.New(src).Shuffle(len(deck), func(i, j int) {
 			deck[i], deck[j] = deck[j], deck[i]
 		})
 		return deck
 	}
 }
 
-// OptionAddJokers adds n arbitary Jokers to the end of a deck
+// OptionShuffleSecure shuffles a deck with crypto/rand, so the order of the
+// cards can't be predicted from earlier deals
+func OptionShuffleSecure() Option {
+	return OptionShuffleWithSource(cryptoSource{})
+}
+
+// OptionAddJokers adds n Jokers to the end of a deck, numbered after the
+// ones already in it so they can be told apart
 func OptionAddJokers(n int) Option {
 	return func(deck []Card) []Card {
+		var last Value
+		for _, c := range deck {
+			if c.Suit == SuitJoker && c.Value > last {
+				last = c.Value
+			}
+		}
 		for i := 1; i <= n; i++ {
-			deck = append(deck, Card{Suit: SuitJoker})
+			deck = append(deck, Card{Suit: SuitJoker, Value: last + Value(i)})
 		}
 		return deck
 	}
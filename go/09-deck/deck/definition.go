@@ -0,0 +1,95 @@
+package deck
+
+// Definition describes a kind of deck by the Cards it's made of
+type Definition struct {
+	Name string
+	// Suits and Values are combined into a Card of every Value in every
+	// Suit, in order
+	Suits  []Suit
+	Values []Value
+	// Copies is the number of times every combination is in the deck, and
+	// is 1 if it's 0
+	Copies int
+	// Extra are added after the combinations, as in the trumps of a Tarot
+	// deck
+	Extra []Card
+}
+
+var frenchSuits = []Suit{SuitSpades, SuitHearts, SuitDiamonds, SuitClubs}
+
+// The list of Definitions of common decks
+var (
+	// French is the standard 52-card deck
+	French = Definition{
+		Name:  "french",
+		Suits: frenchSuits,
+		Values: []Value{
+			ValueTwo, ValueThree, ValueFour, ValueFive, ValueSix, ValueSeven,
+			ValueEight, ValueNine, ValueTen, ValueJack, ValueQueen, ValueKing, ValueAce,
+		},
+	}
+	// Piquet is the 32-card deck of piquet and belote, from seven to ace
+	Piquet = Definition{
+		Name:   "piquet",
+		Suits:  frenchSuits,
+		Values: []Value{ValueSeven, ValueEight, ValueNine, ValueTen, ValueJack, ValueQueen, ValueKing, ValueAce},
+	}
+	// Pinochle is made of two copies of every card from nine to ace, 48 in
+	// all
+	Pinochle = Definition{
+		Name:   "pinochle",
+		Suits:  frenchSuits,
+		Values: []Value{ValueNine, ValueTen, ValueJack, ValueQueen, ValueKing, ValueAce},
+		Copies: 2,
+	}
+	// Spanish is the 40-card Spanish deck, where the sota, caballo and rey
+	// are the Jack, Knight and King, and the as ranks above them
+	Spanish = Definition{
+		Name:  "spanish",
+		Suits: []Suit{SuitCoins, SuitCups, SuitSwords, SuitBatons},
+		Values: []Value{
+			ValueTwo, ValueThree, ValueFour, ValueFive, ValueSix, ValueSeven,
+			ValueJack, ValueKnight, ValueKing, ValueAce,
+		},
+	}
+	// Tarot is the 78-card French Tarot deck, with 56 suited cards, 21
+	// trumps and the excuse
+	Tarot = Definition{
+		Name:  "tarot",
+		Suits: frenchSuits,
+		Values: []Value{
+			ValueOne, ValueTwo, ValueThree, ValueFour, ValueFive, ValueSix, ValueSeven,
+			ValueEight, ValueNine, ValueTen, ValueJack, ValueKnight, ValueQueen, ValueKing,
+		},
+		Extra: tarotTrumps(),
+	}
+)
+
+func tarotTrumps() []Card {
+	var trumps []Card
+	for v := 1; v <= 21; v++ {
+		trumps = append(trumps, Card{Suit: SuitTrumps, Value: Value(v)})
+	}
+	return append(trumps, Card{Suit: SuitTrumps})
+}
+
+// New creates a new deck of the Definition with the specified Options
+func (d Definition) New(opts ...Option) []Card {
+	copies := d.Copies
+	if copies < 1 {
+		copies = 1
+	}
+	deck := make([]Card, 0, copies*len(d.Suits)*len(d.Values)+len(d.Extra))
+	for i := 0; i < copies; i++ {
+		for _, suit := range d.Suits {
+			for _, value := range d.Values {
+				deck = append(deck, Card{Suit: suit, Value: value})
+			}
+		}
+	}
+	deck = append(deck, d.Extra...)
+	for _, opt := range opts {
+		deck = opt(deck)
+	}
+	return deck
+}
@@ -0,0 +1,54 @@
+package deck
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestDefinition(t *testing.T) {
+	cases := []struct {
+		def    Definition
+		size   int
+		hearts int
+	}{
+		{French, 52, 13},
+		{Piquet, 32, 8},
+		{Pinochle, 48, 12},
+		{Spanish, 40, 0},
+		{Tarot, 78, 14},
+	}
+	for _, c := range cases {
+		d := c.def.New(OptionShuffle())
+		if len(d) != c.size {
+			t.Errorf("%s.New(): want %d cards, got %d", c.def.Name, c.size, len(d))
+		}
+		for _, card := range d {
+			if _, err := card.MarshalText(); err != nil {
+				t.Errorf("%s.New(): %v", c.def.Name, err)
+			}
+		}
+
+		hearts := c.def.New(OptionShuffle(), OptionExclude(func(card Card) bool {
+			return card.Suit != SuitHearts
+		}))
+		if len(hearts) != c.hearts {
+			t.Errorf("%s.New(OptionExclude()): want %d hearts, got %d", c.def.Name, c.hearts, len(hearts))
+		}
+
+		sorted := c.def.New(OptionShuffle(), OptionSort(SortDefault))
+		if !sort.SliceIsSorted(sorted, func(i, j int) bool {
+			return SortDefault(sorted[i], sorted[j])
+		}) {
+			t.Errorf("%s.New(OptionSort(SortDefault)): want a sorted deck, got %v", c.def.Name, sorted)
+		}
+		// without copies or extras, sorting gives back the deck's own order
+		if c.def.Copies == 0 && len(c.def.Extra) == 0 {
+			for i, card := range c.def.New() {
+				if sorted[i] != card {
+					t.Errorf("%s.New(OptionSort(SortDefault)): want %v at %d, got %v", c.def.Name, card, i, sorted[i])
+					break
+				}
+			}
+		}
+	}
+}
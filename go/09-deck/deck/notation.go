@@ -0,0 +1,160 @@
+package deck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// shortValues are the Values' letters in short notation
+var shortValues = map[Value]string{
+	ValueTen:    "T",
+	ValueJack:   "J",
+	ValueQueen:  "Q",
+	ValueKing:   "K",
+	ValueAce:    "A",
+	ValueKnight: "C",
+}
+
+// Short returns the Card in short notation, as in "As", "Td", "Joker1" or
+// "Trump21"
+func (c Card) Short() string {
+	switch c.Suit {
+	case SuitJoker:
+		if c.Value == 0 {
+			return "Joker"
+		}
+		return "Joker" + strconv.Itoa(int(c.Value))
+	case SuitTrumps:
+		if c.Value == 0 {
+			return "Excuse"
+		}
+		return "Trump" + strconv.Itoa(int(c.Value))
+	}
+	v, ok := shortValues[c.Value]
+	if !ok {
+		v = strconv.Itoa(int(c.Value))
+	}
+	return v + c.Suit.ASCII()
+}
+
+// ParseCard parses a Card in short notation, as in "As", "Td", "10h", "Q♥",
+// "Joker", "Joker2", "Cb" (the Knight of Batons), "Trump21" or "Excuse",
+// ignoring case
+func ParseCard(s string) (Card, error) {
+	t := strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case t == "joker":
+		return Card{Suit: SuitJoker}, nil
+	case t == "excuse":
+		return Card{Suit: SuitTrumps}, nil
+	case strings.HasPrefix(t, "joker"):
+		n, err := strconv.Atoi(t[len("joker"):])
+		if err != nil || n < 1 {
+			return Card{}, fmt.Errorf("deck: invalid card %q", s)
+		}
+		return Card{Suit: SuitJoker, Value: Value(n)}, nil
+	case strings.HasPrefix(t, "trump"):
+		n, err := strconv.Atoi(t[len("trump"):])
+		if err != nil || n < 1 || n > 21 {
+			return Card{}, fmt.Errorf("deck: invalid card %q", s)
+		}
+		return Card{Suit: SuitTrumps, Value: Value(n)}, nil
+	}
+
+	var suit Suit
+	switch {
+	case strings.HasSuffix(t, "s"), strings.HasSuffix(t, "♠"):
+		suit = SuitSpades
+	case strings.HasSuffix(t, "h"), strings.HasSuffix(t, "♥"):
+		suit = SuitHearts
+	case strings.HasSuffix(t, "d"), strings.HasSuffix(t, "♦"):
+		suit = SuitDiamonds
+	case strings.HasSuffix(t, "c"), strings.HasSuffix(t, "♣"):
+		suit = SuitClubs
+	case strings.HasSuffix(t, "o"):
+		suit = SuitCoins
+	case strings.HasSuffix(t, "p"):
+		suit = SuitCups
+	case strings.HasSuffix(t, "e"):
+		suit = SuitSwords
+	case strings.HasSuffix(t, "b"):
+		suit = SuitBatons
+	default:
+		return Card{}, fmt.Errorf("deck: invalid card %q", s)
+	}
+	v := strings.TrimSpace(strings.TrimSuffix(t, suit.String()))
+	if v == t {
+		v = strings.TrimSpace(t[:len(t)-1])
+	}
+
+	var value Value
+	switch v {
+	case "t", "10":
+		value = ValueTen
+	case "j":
+		value = ValueJack
+	case "q":
+		value = ValueQueen
+	case "k":
+		value = ValueKing
+	case "a":
+		value = ValueAce
+	case "c":
+		value = ValueKnight
+	default:
+		n, err := strconv.Atoi(v)
+		if err != nil || n < int(ValueOne) || n > int(ValueNine) {
+			return Card{}, fmt.Errorf("deck: invalid card %q", s)
+		}
+		value = Value(n)
+	}
+	return Card{Suit: suit, Value: value}, nil
+}
+
+// ParseCards parses space or comma separated Cards in short notation, as in
+// "As Kd 10h"
+func ParseCards(s string) ([]Card, error) {
+	var cards []Card
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == ','
+	}) {
+		c, err := ParseCard(f)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, c)
+	}
+	return cards, nil
+}
+
+// MarshalText encodes the Card in short notation, which is also how it's
+// encoded in JSON
+func (c Card) MarshalText() ([]byte, error) {
+	if !c.valid() {
+		return nil, fmt.Errorf("deck: invalid card %#v", c)
+	}
+	return []byte(c.Short()), nil
+}
+
+// UnmarshalText decodes a Card in short notation
+func (c *Card) UnmarshalText(text []byte) error {
+	card, err := ParseCard(string(text))
+	if err != nil {
+		return err
+	}
+	*c = card
+	return nil
+}
+
+func (c Card) valid() bool {
+	switch {
+	case c.Suit == SuitJoker:
+		return c.Value >= 0
+	case c.Suit == SuitTrumps:
+		return c.Value >= 0 && c.Value <= 21
+	case c.Suit < SuitSpades || c.Suit > SuitTrumps:
+		return false
+	}
+	return c.Value >= ValueOne && c.Value <= ValueKnight
+}
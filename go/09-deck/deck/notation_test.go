@@ -0,0 +1,74 @@
+package deck
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseCard(t *testing.T) {
+	cases := []struct {
+		input string
+		want  Card
+		short string
+		err   bool
+	}{
+		{input: "As", want: Card{Suit: SuitSpades, Value: ValueAce}, short: "As"},
+		{input: "Td", want: Card{Suit: SuitDiamonds, Value: ValueTen}, short: "Td"},
+		{input: "10h", want: Card{Suit: SuitHearts, Value: ValueTen}, short: "Th"},
+		{input: "2C", want: Card{Suit: SuitClubs, Value: ValueTwo}, short: "2c"},
+		{input: "Q♥", want: Card{Suit: SuitHearts, Value: ValueQueen}, short: "Qh"},
+		{input: "Joker", want: Card{Suit: SuitJoker}, short: "Joker"},
+		{input: "joker2", want: Card{Suit: SuitJoker, Value: 2}, short: "Joker2"},
+		{input: "Cb", want: Card{Suit: SuitBatons, Value: ValueKnight}, short: "Cb"},
+		{input: "1h", want: Card{Suit: SuitHearts, Value: ValueOne}, short: "1h"},
+		{input: "Trump21", want: Card{Suit: SuitTrumps, Value: 21}, short: "Trump21"},
+		{input: "excuse", want: Card{Suit: SuitTrumps}, short: "Excuse"},
+		{input: "0s", err: true},
+		{input: "Trump22", err: true},
+		{input: "Ax", err: true},
+		{input: "Joker0", err: true},
+		{input: "", err: true},
+	}
+	for _, c := range cases {
+		got, err := ParseCard(c.input)
+		if c.err {
+			if err == nil {
+				t.Errorf("ParseCard(%q): want an error, got %v", c.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseCard(%q) received an error: %v", c.input, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("ParseCard(%q): want %#v, got %#v", c.input, c.want, got)
+		}
+		if got.Short() != c.short {
+			t.Errorf("ParseCard(%q).Short(): want %q, got %q", c.input, c.short, got.Short())
+		}
+	}
+}
+
+func TestCardJSON(t *testing.T) {
+	cards := New(OptionAddJokers(2))
+	b, err := json.Marshal(cards)
+	if err != nil {
+		t.Fatalf("json.Marshal() received an error: %v", err)
+	}
+	var got []Card
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal() received an error: %v", err)
+	}
+	if len(got) != len(cards) {
+		t.Fatalf("json.Unmarshal(): want %d cards, got %d", len(cards), len(got))
+	}
+	for i := range cards {
+		if got[i] != cards[i] {
+			t.Errorf("json.Unmarshal(): want %v at %d, got %v", cards[i], i, got[i])
+		}
+	}
+	if got[52] == got[53] {
+		t.Errorf("OptionAddJokers(2): want distinguishable jokers, got %v twice", got[52])
+	}
+}
@@ -0,0 +1,60 @@
+package deck
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestOptionShuffleWithSource(t *testing.T) {
+	a := New(OptionShuffleWithSource(rand.NewSource(42)))
+	b := New(OptionShuffleWithSource(rand.NewSource(42)))
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Errorf("OptionShuffleWithSource(): want the same seed to shuffle the same way, got %v and %v", a, b)
+	}
+	if c := New(OptionShuffleWithSource(rand.NewSource(43))); fmt.Sprint(a) == fmt.Sprint(c) {
+		t.Errorf("OptionShuffleWithSource(): want different seeds to shuffle differently, got %v twice", a)
+	}
+}
+
+// TestShuffleUnbiased shuffles a 4-card deck many times, and checks with a
+// chi-squared test that each of its 24 orders comes up as often as the others
+func TestShuffleUnbiased(t *testing.T) {
+	const (
+		shuffles = 48000
+		// the critical value of the chi-squared distribution with 23 degrees
+		// of freedom at p = 0.0001, so a fair shuffle fails once in 10000 runs
+		critical = 56.81
+	)
+	small := func(c Card) bool {
+		return c.Suit != SuitSpades || c.Value > ValueFive
+	}
+
+	cases := []struct {
+		name   string
+		option Option
+	}{
+		{"OptionShuffleWithSource", OptionShuffleWithSource(rand.NewSource(1))},
+		{"OptionShuffle", OptionShuffle()},
+		{"OptionShuffleSecure", OptionShuffleSecure()},
+	}
+	for _, c := range cases {
+		counts := map[string]int{}
+		for i := 0; i < shuffles; i++ {
+			counts[fmt.Sprint(New(OptionExclude(small), c.option))]++
+		}
+		if len(counts) != 24 {
+			t.Errorf("%s(): want 24 orders of 4 cards, got %d", c.name, len(counts))
+			continue
+		}
+		expected := float64(shuffles) / 24
+		var chi2 float64
+		for _, n := range counts {
+			d := float64(n) - expected
+			chi2 += d * d / expected
+		}
+		if chi2 > critical {
+			t.Errorf("%s(): want a chi-squared statistic under %v, got %v", c.name, critical, chi2)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package deck
+
+import "math"
+
+// DefaultPenetration is the share of a shoe dealt before its cut card when
+// none is given
+const DefaultPenetration = 0.75
+
+// Shoe holds several decks of Cards dealt one after the other, until the cut
+// card is reached and the shoe needs to be reshuffled
+type Shoe struct {
+	def         Definition
+	decks       int
+	penetration float64
+	opts        []Option
+
+	cards []Card
+	next  int
+	cut   int
+}
+
+// NewShoe creates a shoe of n French decks, with the cut card placed after
+// the given penetration of the shoe, as in 0.75 to deal three quarters of it,
+// or DefaultPenetration if it's not between 0 and 1. The opts are applied to
+// the whole shoe every time it's reshuffled, and default to OptionShuffle().
+func NewShoe(n int, penetration float64, opts ...Option) *Shoe {
+	return NewShoeOf(French, n, penetration, opts...)
+}
+
+// NewShoeOf creates a shoe of n decks of the Definition, as NewShoe does
+func NewShoeOf(def Definition, n int, penetration float64, opts ...Option) *Shoe {
+	if n < 1 {
+		n = 1
+	}
+	if penetration <= 0 || penetration > 1 {
+		penetration = DefaultPenetration
+	}
+	if len(opts) == 0 {
+		opts = []Option{OptionShuffle()}
+	}
+	s := &Shoe{def: def, decks: n, penetration: penetration, opts: opts}
+	s.Reshuffle()
+	return s
+}
+
+// Reshuffle puts every card back in the shoe, applies the shoe's Options to
+// them and places the cut card again
+func (s *Shoe) Reshuffle() {
+	var cards []Card
+	for i := 0; i < s.decks; i++ {
+		cards = append(cards, s.def.New()...)
+	}
+	for _, opt := range s.opts {
+		cards = opt(cards)
+	}
+	s.cards = cards
+	s.next = 0
+	s.cut = int(math.Round(float64(len(cards)) * s.penetration))
+	if s.cut < 1 {
+		s.cut = 1
+	}
+}
+
+// Draw deals the next card of the shoe. Dealing goes on past the cut card,
+// so a round can be finished, but the shoe is reshuffled if it runs out of
+// cards. It returns false if there are no cards even then, as when the
+// shoe's Options exclude all of them.
+func (s *Shoe) Draw() (Card, bool) {
+	if s.next >= len(s.cards) {
+		s.Reshuffle()
+	}
+	if s.next >= len(s.cards) {
+		return Card{}, false
+	}
+	c := s.cards[s.next]
+	s.next++
+	return c, true
+}
+
+// Remaining returns the number of cards left in the shoe
+func (s *Shoe) Remaining() int {
+	return len(s.cards) - s.next
+}
+
+// NeedsReshuffle reports whether the cut card was reached, meaning the shoe
+// should be reshuffled before the next round
+func (s *Shoe) NeedsReshuffle() bool {
+	return s.next >= s.cut
+}
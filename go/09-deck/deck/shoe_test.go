@@ -0,0 +1,81 @@
+package deck
+
+import "testing"
+
+func TestShoe(t *testing.T) {
+	cases := []struct {
+		decks       int
+		penetration float64
+		size        int
+		cut         int
+	}{
+		{decks: 1, penetration: 0.5, size: 52, cut: 26},
+		{decks: 6, penetration: 0.75, size: 312, cut: 234},
+		{decks: 8, penetration: 0, size: 416, cut: 312},
+		{decks: 0, penetration: 1, size: 52, cut: 52},
+	}
+	for _, c := range cases {
+		s := NewShoe(c.decks, c.penetration)
+		if got := s.Remaining(); got != c.size {
+			t.Errorf("NewShoe(%d, %v).Remaining(): want %d, got %d", c.decks, c.penetration, c.size, got)
+		}
+
+		counts := map[Card]int{}
+		for i := 0; i < c.cut; i++ {
+			if s.NeedsReshuffle() {
+				t.Fatalf("NewShoe(%d, %v).NeedsReshuffle(): want false after %d cards, got true", c.decks, c.penetration, i)
+			}
+			card, ok := s.Draw()
+			if !ok {
+				t.Fatalf("NewShoe(%d, %v).Draw(): want a card after %d cards, got none", c.decks, c.penetration, i)
+			}
+			counts[card]++
+		}
+		if !s.NeedsReshuffle() {
+			t.Errorf("NewShoe(%d, %v).NeedsReshuffle(): want true after %d cards, got false", c.decks, c.penetration, c.cut)
+		}
+		decks := c.decks
+		if decks < 1 {
+			decks = 1
+		}
+		for card, n := range counts {
+			if n > decks {
+				t.Errorf("NewShoe(%d, %v).Draw(): dealt %v %d times", c.decks, c.penetration, card, n)
+			}
+		}
+
+		for s.Remaining() > 0 {
+			s.Draw()
+		}
+		if _, ok := s.Draw(); !ok {
+			t.Errorf("NewShoe(%d, %v).Draw(): want a card from the reshuffled shoe, got none", c.decks, c.penetration)
+		}
+		if got := s.Remaining(); got != c.size-1 {
+			t.Errorf("NewShoe(%d, %v).Draw(): want an empty shoe to be reshuffled, got %d cards left", c.decks, c.penetration, got)
+		}
+	}
+}
+
+func TestShoeOf(t *testing.T) {
+	s := NewShoeOf(Pinochle, 2, 1)
+	if got := s.Remaining(); got != 96 {
+		t.Errorf("NewShoeOf(Pinochle, 2, 1).Remaining(): want 96, got %d", got)
+	}
+	for s.Remaining() > 0 {
+		card, _ := s.Draw()
+		if card.Value < ValueNine {
+			t.Errorf("NewShoeOf(Pinochle, 2, 1).Draw(): want a pinochle card, got %v", card)
+		}
+	}
+	if _, ok := s.Draw(); !ok || s.Remaining() != 95 {
+		t.Errorf("NewShoeOf(Pinochle, 2, 1).Draw(): want the shoe reshuffled, got %d cards left", s.Remaining())
+	}
+
+	empty := NewShoe(1, 0.5, OptionExclude(func(Card) bool { return true }))
+	if got := empty.Remaining(); got != 0 {
+		t.Errorf("NewShoe() excluding every card: want 0 cards, got %d", got)
+	}
+	if card, ok := empty.Draw(); ok {
+		t.Errorf("NewShoe().Draw() excluding every card: want no card, got %v", card)
+	}
+}
@@ -0,0 +1,79 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/ramin0/live/go/deck/deck"
+)
+
+// Equity estimates the share of the pot each of the hands wins on average,
+// ties splitting it, by dealing the rest of a 5-card board trials times from
+// the cards of a deck no one holds, shuffled with src
+func Equity(hands [][]deck.Card, board []deck.Card, trials int, src rand.Source) ([]float64, error) {
+	if len(board) > 5 {
+		return nil, errors.New("poker: a board can't have more than 5 cards")
+	}
+	if trials <= 0 {
+		return nil, errors.New("poker: the number of trials must be positive")
+	}
+	known := map[deck.Card]bool{}
+	for _, cards := range append([][]deck.Card{board}, hands...) {
+		for _, c := range cards {
+			if known[c] {
+				return nil, fmt.Errorf("poker: %v is dealt more than once", c)
+			}
+			known[c] = true
+		}
+	}
+	var rest []deck.Card
+	for _, c := range deck.New() {
+		if !known[c] {
+			rest = append(rest, c)
+		}
+	}
+	missing := 5 - len(board)
+	if missing > len(rest) {
+		return nil, errors.New("poker: not enough cards left to deal the board")
+	}
+
+	r := rand.New(src)
+	equity := make([]float64, len(hands))
+	ranks := make([]Rank, len(hands))
+	cards := make([]deck.Card, 0, 7)
+	for t := 0; t < trials; t++ {
+		// a partial Fisher-Yates shuffle deals the missing cards to the
+		// front of rest
+		for i := 0; i < missing; i++ {
+			j := i + r.Intn(len(rest)-i)
+			rest[i], rest[j] = rest[j], rest[i]
+		}
+
+		var best Rank
+		winners := 0
+		for i, h := range hands {
+			cards = append(append(append(cards[:0], h...), board...), rest[:missing]...)
+			rank, err := Evaluate(cards)
+			if err != nil {
+				return nil, err
+			}
+			ranks[i] = rank
+			switch {
+			case rank > best:
+				best, winners = rank, 1
+			case rank == best:
+				winners++
+			}
+		}
+		for i, rank := range ranks {
+			if rank == best {
+				equity[i] += 1 / float64(winners)
+			}
+		}
+	}
+	for i := range equity {
+		equity[i] /= float64(trials)
+	}
+	return equity, nil
+}
@@ -0,0 +1,281 @@
+// Package poker ranks poker hands made of cards from the deck package
+package poker
+
+import (
+	"errors"
+	"math/bits"
+
+	"github.com/ramin0/live/go/deck/deck"
+)
+
+// Category is the kind of a poker hand, as in a flush or a full house
+type Category int
+
+// The list of Categories, from the weakest to the strongest. FiveOfAKind can
+// only be made with jokers.
+const (
+	HighCard Category = iota
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	Straight
+	Flush
+	FullHouse
+	FourOfAKind
+	StraightFlush
+	RoyalFlush
+	FiveOfAKind
+)
+
+func (c Category) String() string {
+	switch c {
+	case HighCard:
+		return "High Card"
+	case OnePair:
+		return "One Pair"
+	case TwoPair:
+		return "Two Pair"
+	case ThreeOfAKind:
+		return "Three of a Kind"
+	case Straight:
+		return "Straight"
+	case Flush:
+		return "Flush"
+	case FullHouse:
+		return "Full House"
+	case FourOfAKind:
+		return "Four of a Kind"
+	case StraightFlush:
+		return "Straight Flush"
+	case RoyalFlush:
+		return "Royal Flush"
+	case FiveOfAKind:
+		return "Five of a Kind"
+	}
+	return ""
+}
+
+// Rank is the strength of a poker hand, a stronger hand having a higher
+// Rank, and hands of the same strength the same Rank. It holds the hand's
+// Category, followed by the values of the cards breaking ties within it,
+// kickers included.
+type Rank uint32
+
+// Category returns the Category of the hand
+func (r Rank) Category() Category {
+	return Category(r >> 20)
+}
+
+// Values returns the values deciding between hands of the same Category,
+// in the order they're compared in
+func (r Rank) Values() []deck.Value {
+	var values []deck.Value
+	for shift := 16; shift >= 0; shift -= 4 {
+		if v := deck.Value(r >> uint(shift) & 0xf); v != 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func (r Rank) String() string {
+	return r.Category().String()
+}
+
+func newRank(c Category, values ...int) Rank {
+	r := Rank(c) << 20
+	for i, v := range values {
+		r |= Rank(v) << uint(16-4*i)
+	}
+	return r
+}
+
+// The list of errors Evaluate can return
+var (
+	// ErrHandSize is returned when evaluating a hand of less than 5 or more
+	// than 7 cards
+	ErrHandSize = errors.New("poker: a hand must have 5 to 7 cards")
+	// ErrInvalidCard is returned when evaluating a hand with cards that
+	// aren't jokers or from a French deck
+	ErrInvalidCard = errors.New("poker: a hand can only have cards of a French deck and jokers")
+)
+
+const (
+	aceLow  = 1
+	aceHigh = int(deck.ValueAce)
+	// allValues has a bit set for every value, from two to ace
+	allValues = uint16(0x7ffc)
+)
+
+// Evaluate ranks the best 5-card poker hand that can be made out of 5 to 7
+// cards, jokers being wild
+func Evaluate(cards []deck.Card) (Rank, error) {
+	if len(cards) < 5 || len(cards) > 7 {
+		return 0, ErrHandSize
+	}
+
+	// counts holds the number of cards of every value, suits the values of
+	// every suit as bits, and present the values of all suits as bits
+	var counts [15]int
+	var suits [4]uint16
+	var present uint16
+	jokers := 0
+	for _, c := range cards {
+		if c.Suit == deck.SuitJoker {
+			jokers++
+			continue
+		}
+		if c.Suit < deck.SuitSpades || c.Suit > deck.SuitClubs || c.Value < deck.ValueTwo || c.Value > deck.ValueAce {
+			return 0, ErrInvalidCard
+		}
+		v := int(c.Value)
+		counts[v]++
+		suits[c.Suit] |= 1 << uint(v)
+		present |= 1 << uint(v)
+	}
+
+	// five of a kind
+	for v := aceHigh; v >= 2; v-- {
+		if counts[v]+jokers >= 5 {
+			return newRank(FiveOfAKind, v), nil
+		}
+	}
+
+	// straight flush
+	best := -1
+	for _, s := range suits {
+		if top := straightTop(s, jokers); top > best {
+			best = top
+		}
+	}
+	if best == aceHigh {
+		return newRank(RoyalFlush, best), nil
+	}
+	if best > 0 {
+		return newRank(StraightFlush, best), nil
+	}
+
+	// four of a kind
+	for v := aceHigh; v >= 2; v-- {
+		if need := 4 - counts[v]; need <= jokers {
+			return newRank(FourOfAKind, append([]int{v}, kickers(present, 1<<uint(v), jokers-max0(need), 1)...)...), nil
+		}
+	}
+
+	// full house, with the highest three of a kind first
+	for t := aceHigh; t >= 2; t-- {
+		needT := max0(3 - counts[t])
+		if needT > jokers {
+			continue
+		}
+		for p := aceHigh; p >= 2; p-- {
+			if p != t && needT+max0(2-counts[p]) <= jokers {
+				return newRank(FullHouse, t, p), nil
+			}
+		}
+	}
+
+	// flush
+	var flush []int
+	for _, s := range suits {
+		if bits.OnesCount16(s)+jokers < 5 {
+			continue
+		}
+		if f := kickers(s, 0, jokers, 5); flush == nil || compareValues(f, flush) > 0 {
+			flush = f
+		}
+	}
+	if flush != nil {
+		return newRank(Flush, flush...), nil
+	}
+
+	// straight
+	if top := straightTop(present, jokers); top > 0 {
+		return newRank(Straight, top), nil
+	}
+
+	// three of a kind
+	for v := aceHigh; v >= 2; v-- {
+		if need := 3 - counts[v]; need <= jokers {
+			return newRank(ThreeOfAKind, append([]int{v}, kickers(present, 1<<uint(v), jokers-max0(need), 2)...)...), nil
+		}
+	}
+
+	// two pair and one pair, which can't be made with jokers since a joker
+	// and a pair make three of a kind
+	var pairs []int
+	for v := aceHigh; v >= 2 && len(pairs) < 2; v-- {
+		if counts[v] >= 2 {
+			pairs = append(pairs, v)
+		}
+	}
+	switch {
+	case len(pairs) == 2:
+		return newRank(TwoPair, pairs[0], pairs[1], kickers(present, 1<<uint(pairs[0])|1<<uint(pairs[1]), 0, 1)[0]), nil
+	case len(pairs) == 1:
+		return newRank(OnePair, append([]int{pairs[0]}, kickers(present, 1<<uint(pairs[0]), 0, 3)...)...), nil
+	case jokers > 0:
+		// a single joker pairs up with the highest card
+		v := kickers(present, 0, 0, 1)[0]
+		return newRank(OnePair, append([]int{v}, kickers(present, 1<<uint(v), 0, 3)...)...), nil
+	}
+	return newRank(HighCard, kickers(present, 0, 0, 5)...), nil
+}
+
+// straightTop returns the value of the highest card of the best straight
+// that can be made with the values in set and jokers, or -1 if there's none
+func straightTop(set uint16, jokers int) int {
+	if set&(1<<uint(aceHigh)) != 0 {
+		set |= 1 << aceLow
+	}
+	if bits.OnesCount16(set)+jokers < 5 {
+		return -1
+	}
+	for top := aceHigh; top >= 5; top-- {
+		window := uint16(0x1f) << uint(top-4)
+		if 5-bits.OnesCount16(set&window) <= jokers {
+			return top
+		}
+	}
+	return -1
+}
+
+// kickers returns the n highest distinct values in set but not in excluded,
+// jokers standing for the highest values missing from set
+func kickers(set, excluded uint16, jokers, n int) []int {
+	set &^= excluded
+	for v := aceHigh; v >= 2 && jokers > 0; v-- {
+		bit := uint16(1) << uint(v)
+		if set&bit == 0 && excluded&bit == 0 {
+			set |= bit
+			jokers--
+		}
+	}
+	set &= allValues
+	values := make([]int, 0, n)
+	for len(values) < n && set != 0 {
+		v := 15 - bits.LeadingZeros16(set)
+		values = append(values, v)
+		set &^= 1 << uint(v)
+	}
+	return values
+}
+
+func compareValues(a, b []int) int {
+	for i := range a {
+		if i >= len(b) || a[i] > b[i] {
+			return 1
+		}
+		if a[i] < b[i] {
+			return -1
+		}
+	}
+	return 0
+}
+
+func max0(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,202 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/ramin0/live/go/deck/deck"
+)
+
+func mustParse(t testing.TB, s string) []deck.Card {
+	cards, err := deck.ParseCards(s)
+	if err != nil {
+		t.Fatalf("deck.ParseCards(%q) received an error: %v", s, err)
+	}
+	return cards
+}
+
+func TestEvaluate(t *testing.T) {
+	cases := []struct {
+		hand   string
+		want   Category
+		values string
+	}{
+		{"As Kd 9h 7c 2s", HighCard, "[A K 9 7 2]"},
+		{"As Ad 9h 7c 2s", OnePair, "[A 9 7 2]"},
+		{"As Ad 9h 9c 2s", TwoPair, "[A 9 2]"},
+		{"As Ad 9h 9c 2s 2d Kh", TwoPair, "[A 9 K]"},
+		{"7s 7d 7h Kc 2s", ThreeOfAKind, "[7 K 2]"},
+		{"As 2d 3h 4c 5s", Straight, "[5]"},
+		{"Ts Jd Qh Kc As", Straight, "[A]"},
+		{"2h 7h 9h Jh Kh 3s", Flush, "[K J 9 7 2]"},
+		{"7s 7d 7h Kc Ks", FullHouse, "[7 K]"},
+		{"7s 7d 7h Kc Ks Kh 2d", FullHouse, "[K 7]"},
+		{"7s 7d 7h 7c Ks", FourOfAKind, "[7 K]"},
+		{"5h 6h 7h 8h 9h Th 2c", StraightFlush, "[10]"},
+		{"Tc Jc Qc Kc Ac", RoyalFlush, "[A]"},
+		// jokers are wild
+		{"As Kd 9h 7c Joker", OnePair, "[A K 9 7]"},
+		{"As Ad 9h 7c Joker1", ThreeOfAKind, "[A 9 7]"},
+		{"As Ad 9h 9c Joker", FullHouse, "[A 9]"},
+		{"2h 7h 9h Jh Joker", Flush, "[A J 9 7 2]"},
+		{"9s Td Jh Qc Joker", Straight, "[K]"},
+		{"As Ad Ah Ac Joker", FiveOfAKind, "[A]"},
+		{"Th Jh Qh Joker1 Joker2 2c 3d", RoyalFlush, "[A]"},
+		{"2c 3d 4h Joker1 Joker2 9s Ks", Straight, "[6]"},
+	}
+	for _, c := range cases {
+		rank, err := Evaluate(mustParse(t, c.hand))
+		if err != nil {
+			t.Errorf("Evaluate(%s) received an error: %v", c.hand, err)
+			continue
+		}
+		if rank.Category() != c.want {
+			t.Errorf("Evaluate(%s): want %v, got %v", c.hand, c.want, rank.Category())
+		}
+		if got := fmt.Sprint(rank.Values()); got != c.values {
+			t.Errorf("Evaluate(%s).Values(): want %v, got %v", c.hand, c.values, got)
+		}
+	}
+
+	if _, err := Evaluate(mustParse(t, "As Kd 9h 7c")); err != ErrHandSize {
+		t.Errorf("Evaluate() with 4 cards: want %v, got %v", ErrHandSize, err)
+	}
+	if _, err := Evaluate(mustParse(t, "As Kd 9h 7c Co")); err != ErrInvalidCard {
+		t.Errorf("Evaluate() with a Spanish card: want %v, got %v", ErrInvalidCard, err)
+	}
+	hand := append(mustParse(t, "As Kd 9h 7c"), deck.Card{Suit: -1, Value: deck.ValueTwo})
+	if _, err := Evaluate(hand); err != ErrInvalidCard {
+		t.Errorf("Evaluate() with a negative suit: want %v, got %v", ErrInvalidCard, err)
+	}
+}
+
+func TestEvaluateCompare(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"As Ad 9h 7c 2s", "As Ad 9h 7c 3s", -1},
+		{"Ks Kd Qh Qc 2s", "Ks Kd Jh Jc As", 1},
+		{"As 2d 3h 4c 5s", "2s 3d 4h 5c 6s", -1},
+		{"2h 7h 9h Jh Kh", "2s 7s 9s Js Ks", 0},
+		{"7s 7d 7h Kc Ks", "6s 6d 6h Ac As", 1},
+		{"As Kd 9h 7c Joker", "Ac Kh 9d 7s 2c", 1},
+	}
+	for _, c := range cases {
+		a, err := Evaluate(mustParse(t, c.a))
+		if err != nil {
+			t.Fatalf("Evaluate(%s) received an error: %v", c.a, err)
+		}
+		b, err := Evaluate(mustParse(t, c.b))
+		if err != nil {
+			t.Fatalf("Evaluate(%s) received an error: %v", c.b, err)
+		}
+		got := 0
+		switch {
+		case a > b:
+			got = 1
+		case a < b:
+			got = -1
+		}
+		if got != c.want {
+			t.Errorf("Evaluate(%s) vs Evaluate(%s): want %d, got %d", c.a, c.b, c.want, got)
+		}
+	}
+}
+
+// TestEvaluateCategories checks the share of every Category among all the
+// 2,598,960 5-card hands
+func TestEvaluateCategories(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the enumeration of every hand in short mode")
+	}
+	want := map[Category]int{
+		HighCard:      1302540,
+		OnePair:       1098240,
+		TwoPair:       123552,
+		ThreeOfAKind:  54912,
+		Straight:      10200,
+		Flush:         5108,
+		FullHouse:     3744,
+		FourOfAKind:   624,
+		StraightFlush: 36,
+		RoyalFlush:    4,
+	}
+	cards := deck.New()
+	got := map[Category]int{}
+	hand := make([]deck.Card, 5)
+	for a := 0; a < 52; a++ {
+		for b := a + 1; b < 52; b++ {
+			for c := b + 1; c < 52; c++ {
+				for d := c + 1; d < 52; d++ {
+					for e := d + 1; e < 52; e++ {
+						hand[0], hand[1], hand[2], hand[3], hand[4] = cards[a], cards[b], cards[c], cards[d], cards[e]
+						rank, err := Evaluate(hand)
+						if err != nil {
+							t.Fatalf("Evaluate(%v) received an error: %v", hand, err)
+						}
+						got[rank.Category()]++
+					}
+				}
+			}
+		}
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Evaluate(): want %v, got %v", want, got)
+	}
+}
+
+func TestEquity(t *testing.T) {
+	// pocket aces against pocket kings win about 82% of the time
+	hands := [][]deck.Card{mustParse(t, "As Ah"), mustParse(t, "Ks Kh")}
+	equity, err := Equity(hands, nil, 20000, rand.NewSource(1))
+	if err != nil {
+		t.Fatalf("Equity() received an error: %v", err)
+	}
+	if math.Abs(equity[0]-0.82) > 0.02 || math.Abs(equity[0]+equity[1]-1) > 1e-9 {
+		t.Errorf("Equity(): want about [0.82 0.18], got %v", equity)
+	}
+
+	cases := []struct {
+		hands  []string
+		board  string
+		trials int
+	}{
+		{hands: []string{"As Ah", "Ks Kh"}, trials: 0},
+		{hands: []string{"As Ah", "Ks Kh"}, trials: -1},
+		{hands: []string{"As Ah", "As Kh"}, trials: 100},
+		{hands: []string{"As Ah", "Ks Kh"}, board: "2c 3d Ah", trials: 100},
+		{hands: []string{"As Ah", "Ks Kh"}, board: "2c 2c 3d", trials: 100},
+	}
+	for _, c := range cases {
+		var hands [][]deck.Card
+		for _, h := range c.hands {
+			hands = append(hands, mustParse(t, h))
+		}
+		var board []deck.Card
+		if c.board != "" {
+			board = mustParse(t, c.board)
+		}
+		if _, err := Equity(hands, board, c.trials, rand.NewSource(1)); err == nil {
+			t.Errorf("Equity(%v, %q, %d): expected an error", c.hands, c.board, c.trials)
+		}
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	cards := deck.New()
+	hands := make([][]deck.Card, 1000)
+	for i := range hands {
+		r.Shuffle(len(cards), func(i, j int) {
+			cards[i], cards[j] = cards[j], cards[i]
+		})
+		hands[i] = append([]deck.Card{}, cards[:7]...)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Evaluate(hands[i%len(hands)])
+	}
+}
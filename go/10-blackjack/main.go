@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"strings"
@@ -16,11 +17,14 @@ const (
 )
 
 func main() {
+	flagHitSoft17 := flag.Bool("h17", false, "Whether the dealer hits on a soft 17.")
+	flag.Parse()
+
 	cards := deck.New(deck.OptionShuffle())
 
 	players := []player{
 		&humanPlayer{},
-		&dealerPlayer{},
+		&dealerPlayer{hitSoft17: *flagHitSoft17},
 	}
 
 	deal := func(p player) {
@@ -43,20 +47,20 @@ Game:
 	Round:
 		for g := 1; ; g++ {
 			for _, p := range players {
-				fmt.Printf("%s: %v, Score: %d\n",
-					p.Name(), p.Hand(), calcScore(p.Hand()))
+				fmt.Printf("%s: %v, Score: %v\n",
+					p.Name(), p.Hand(), scoreHand(p.Hand()))
 			}
 
 			// 4. Determining the winner
 			// TODO: Fix for handling more than 2 players
 			for i := 0; i < len(players); i++ {
 				p := players[i]
-				s := calcScore(p.Hand())
-				if s == 21 {
+				s := scoreHand(p.Hand())
+				if s.Total() == 21 {
 					fmt.Printf("%s won!\n", p.Name())
 					break Game
 				}
-				if s > 21 {
+				if s.Bust() {
 					otherPlayer := players[(i+1)%len(players)]
 					fmt.Printf("%s won!\n", otherPlayer.Name())
 					break Game
@@ -90,26 +94,6 @@ Game:
 	}
 }
 
-func calcScore(cards []deck.Card) (score int) {
-	var aces int
-	for _, c := range cards {
-		switch s := int(c.Value); {
-		case deck.ValueTwo <= c.Value && c.Value <= deck.ValueTen:
-			score += s
-		case c.Value == deck.ValueAce:
-			aces++
-			score++
-			fallthrough // TODO: Remove this
-		case deck.ValueJack <= c.Value && c.Value <= deck.ValueKing:
-			score += 10
-		}
-	}
-
-	// TODO: Decide how to calculate the aces (0 or 10)
-
-	return score
-}
-
 type player interface {
 	Name() string
 	Hand() []deck.Card
@@ -156,11 +140,12 @@ func (p humanPlayer) Play() (playType, error) {
 
 type dealerPlayer struct {
 	basePlayer
+	hitSoft17 bool
 }
 
 func (dealerPlayer) Name() string { return "Dealer" }
 func (p dealerPlayer) Play() (playType, error) {
-	if calcScore(p.hand) <= 16 {
+	if dealerHits(scoreHand(p.hand), p.hitSoft17) {
 		return playTypeHit, nil
 	}
 	return playTypeStand, nil
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ramin0/live/go/blackjack/deck"
+)
+
+// score holds the totals of a blackjack hand
+type score struct {
+	// Hard counts every ace as 1
+	Hard int
+	// Soft counts one ace as 11, and is 0 when the hand has no ace or
+	// counting one as 11 would bust it
+	Soft int
+	// Blackjack is whether the hand is a 21 made of two cards
+	Blackjack bool
+}
+
+// scoreHand returns the score of a blackjack hand, ignoring jokers
+func scoreHand(cards []deck.Card) score {
+	var s score
+	aces := 0
+	for _, c := range cards {
+		switch {
+		case deck.ValueTwo <= c.Value && c.Value <= deck.ValueTen:
+			s.Hard += int(c.Value)
+		case deck.ValueJack <= c.Value && c.Value <= deck.ValueKing:
+			s.Hard += 10
+		case c.Value == deck.ValueAce:
+			aces++
+			s.Hard++
+		}
+	}
+	// two aces counted as 11 would always bust a hand
+	if aces > 0 && s.Hard+10 <= 21 {
+		s.Soft = s.Hard + 10
+	}
+	s.Blackjack = len(cards) == 2 && s.Total() == 21
+	return s
+}
+
+// Total returns the best total of the hand
+func (s score) Total() int {
+	if s.Soft > 0 {
+		return s.Soft
+	}
+	return s.Hard
+}
+
+// IsSoft reports whether an ace counts as 11 in the hand's Total
+func (s score) IsSoft() bool {
+	return s.Soft > 0
+}
+
+// Bust reports whether the hand is over 21
+func (s score) Bust() bool {
+	return s.Hard > 21
+}
+
+func (s score) String() string {
+	switch {
+	case s.Blackjack:
+		return "Blackjack"
+	case s.Bust():
+		return fmt.Sprintf("%d (Bust)", s.Total())
+	case s.IsSoft():
+		return fmt.Sprintf("Soft %d", s.Total())
+	}
+	return fmt.Sprint(s.Total())
+}
+
+// dealerHits reports whether the dealer hits with a hand of score s, which
+// they do under 17, and on a soft 17 if hitSoft17 is true
+func dealerHits(s score, hitSoft17 bool) bool {
+	return s.Total() < 17 || s.Total() == 17 && s.IsSoft() && hitSoft17
+}
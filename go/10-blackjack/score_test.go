@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ramin0/live/go/blackjack/deck"
+)
+
+func hand(values ...deck.Value) []deck.Card {
+	var cards []deck.Card
+	for _, v := range values {
+		cards = append(cards, deck.Card{Suit: deck.SuitSpades, Value: v})
+	}
+	return cards
+}
+
+func TestScoreHand(t *testing.T) {
+	cases := []struct {
+		hand      []deck.Card
+		hard      int
+		soft      int
+		total     int
+		blackjack bool
+		bust      bool
+	}{
+		{hand: hand(deck.ValueTwo, deck.ValueNine), hard: 11, total: 11},
+		{hand: hand(deck.ValueKing, deck.ValueQueen), hard: 20, total: 20},
+		{hand: hand(deck.ValueAce, deck.ValueKing), hard: 11, soft: 21, total: 21, blackjack: true},
+		{hand: hand(deck.ValueAce, deck.ValueSix), hard: 7, soft: 17, total: 17},
+		{hand: hand(deck.ValueAce, deck.ValueAce), hard: 2, soft: 12, total: 12},
+		{hand: hand(deck.ValueAce, deck.ValueFive, deck.ValueFive), hard: 11, soft: 21, total: 21},
+		{hand: hand(deck.ValueAce, deck.ValueSix, deck.ValueTen), hard: 17, total: 17},
+		{hand: hand(deck.ValueAce, deck.ValueAce, deck.ValueAce, deck.ValueEight), hard: 11, soft: 21, total: 21},
+		{hand: hand(deck.ValueTen, deck.ValueSix, deck.ValueJack), hard: 26, total: 26, bust: true},
+		{hand: hand(deck.ValueAce, deck.ValueTen, deck.ValueKing, deck.ValueTwo), hard: 23, total: 23, bust: true},
+		{hand: nil, hard: 0, total: 0},
+	}
+	for _, c := range cases {
+		s := scoreHand(c.hand)
+		if s.Hard != c.hard || s.Soft != c.soft || s.Total() != c.total ||
+			s.Blackjack != c.blackjack || s.Bust() != c.bust {
+			t.Errorf("scoreHand(%v): want hard %d, soft %d, total %d, blackjack %v and bust %v, got hard %d, soft %d, total %d, blackjack %v and bust %v",
+				c.hand, c.hard, c.soft, c.total, c.blackjack, c.bust,
+				s.Hard, s.Soft, s.Total(), s.Blackjack, s.Bust())
+		}
+	}
+}
+
+func TestDealerHits(t *testing.T) {
+	cases := []struct {
+		hand      []deck.Card
+		hitSoft17 bool
+		want      bool
+	}{
+		{hand(deck.ValueTen, deck.ValueSix), false, true},
+		{hand(deck.ValueTen, deck.ValueSeven), false, false},
+		{hand(deck.ValueTen, deck.ValueSeven), true, false},
+		{hand(deck.ValueAce, deck.ValueSix), false, false},
+		{hand(deck.ValueAce, deck.ValueSix), true, true},
+		{hand(deck.ValueAce, deck.ValueSix, deck.ValueTen), true, false},
+		{hand(deck.ValueAce, deck.ValueSeven), true, false},
+	}
+	for _, c := range cases {
+		if got := dealerHits(scoreHand(c.hand), c.hitSoft17); got != c.want {
+			t.Errorf("dealerHits(%v, %v): want %v, got %v", c.hand, c.hitSoft17, c.want, got)
+		}
+	}
+}